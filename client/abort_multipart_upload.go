@@ -0,0 +1,38 @@
+package client
+
+import (
+	"context"
+
+	"github.com/valyala/fasthttp"
+)
+
+type AbortMultipartUploadInput struct {
+	Bucket   string
+	Key      string
+	UploadID string
+}
+
+type AbortMultipartUploadOutput struct{}
+
+// AbortMultipartUpload discards the upload and its parts. Parts being
+// uploaded concurrently may survive, ListParts tells whether another abort
+// is needed.
+func (c *Client) AbortMultipartUpload(ctx context.Context, input *AbortMultipartUploadInput) (*AbortMultipartUploadOutput, error) {
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(resp)
+
+	if err := c.setRequestURI(req, input.Bucket, input.Key); err != nil {
+		return nil, err
+	}
+
+	req.Header.SetMethod(fasthttp.MethodDelete)
+	req.URI().QueryArgs().Add("uploadId", input.UploadID)
+
+	if err := c.doBucket(ctx, input.Bucket, req, resp); err != nil {
+		return nil, err
+	}
+
+	return &AbortMultipartUploadOutput{}, nil
+}
@@ -0,0 +1,345 @@
+package client
+
+import (
+	"crypto/sha1" //nolint:gosec // SHA1 is one of the integrity checksums offered by S3
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"hash/crc64"
+
+	"github.com/lvjp/s3hobby/pkg/s3/api"
+
+	"github.com/valyala/fasthttp"
+)
+
+type ChecksumAlgorithm string
+
+const (
+	ChecksumAlgorithmCRC32     ChecksumAlgorithm = "CRC32"
+	ChecksumAlgorithmCRC32C    ChecksumAlgorithm = "CRC32C"
+	ChecksumAlgorithmCRC64NVME ChecksumAlgorithm = "CRC64NVME"
+	ChecksumAlgorithmSHA1      ChecksumAlgorithm = "SHA1"
+	ChecksumAlgorithmSHA256    ChecksumAlgorithm = "SHA256"
+)
+
+// ChecksumType tells how the checksum of a multipart object is computed.
+type ChecksumType string
+
+const (
+	// ChecksumTypeComposite is the checksum of the part checksums.
+	ChecksumTypeComposite ChecksumType = "COMPOSITE"
+
+	// ChecksumTypeFullObject is the checksum of the whole object content,
+	// only available with the CRC algorithms.
+	ChecksumTypeFullObject ChecksumType = "FULL_OBJECT"
+)
+
+// ErrChecksumMismatch reports a checksum computed by the client which differs
+// from the one computed by S3.
+var ErrChecksumMismatch = errors.New("client: checksum mismatch")
+
+// crc64NVME is the reversed polynomial of CRC-64/NVME.
+const crc64NVME = 0x9a6c9329ac4bc9b5
+
+var crc64NVMETable = crc64.MakeTable(crc64NVME)
+
+func (a ChecksumAlgorithm) newHash() (hash.Hash, error) {
+	switch a {
+	case ChecksumAlgorithmCRC32:
+		return crc32.NewIEEE(), nil
+	case ChecksumAlgorithmCRC32C:
+		return crc32.New(crc32.MakeTable(crc32.Castagnoli)), nil
+	case ChecksumAlgorithmCRC64NVME:
+		return crc64.New(crc64NVMETable), nil
+	case ChecksumAlgorithmSHA1:
+		return sha1.New(), nil //nolint:gosec // See the import comment
+	case ChecksumAlgorithmSHA256:
+		return sha256.New(), nil
+	default:
+		return nil, fmt.Errorf("client: unsupported checksum algorithm %q", a)
+	}
+}
+
+// defaultChecksumType returns the checksum type of multipart objects: the CRC
+// algorithms can be combined over the whole object, the others cannot.
+func (a ChecksumAlgorithm) defaultChecksumType() ChecksumType {
+	switch a {
+	case ChecksumAlgorithmCRC32, ChecksumAlgorithmCRC32C, ChecksumAlgorithmCRC64NVME:
+		return ChecksumTypeFullObject
+	default:
+		return ChecksumTypeComposite
+	}
+}
+
+// computeChecksum returns the base64 encoded checksum of body.
+func computeChecksum(algorithm ChecksumAlgorithm, body []byte) (string, error) {
+	h, err := algorithm.newHash()
+	if err != nil {
+		return "", err
+	}
+
+	h.Write(body)
+
+	return base64.StdEncoding.EncodeToString(h.Sum(nil)), nil
+}
+
+// Checksums holds base64 encoded checksums, only the ones computed by the
+// client or returned by S3 are set.
+type Checksums struct {
+	CRC32     string
+	CRC32C    string
+	CRC64NVME string
+	SHA1      string
+	SHA256    string
+}
+
+// Get returns the checksum of the algorithm, empty when not set.
+func (c *Checksums) Get(algorithm ChecksumAlgorithm) string {
+	if field := c.field(algorithm); field != nil {
+		return *field
+	}
+
+	return ""
+}
+
+func (c *Checksums) field(algorithm ChecksumAlgorithm) *string {
+	switch algorithm {
+	case ChecksumAlgorithmCRC32:
+		return &c.CRC32
+	case ChecksumAlgorithmCRC32C:
+		return &c.CRC32C
+	case ChecksumAlgorithmCRC64NVME:
+		return &c.CRC64NVME
+	case ChecksumAlgorithmSHA1:
+		return &c.SHA1
+	case ChecksumAlgorithmSHA256:
+		return &c.SHA256
+	default:
+		return nil
+	}
+}
+
+// compute sets the checksum of the algorithm unless already set.
+func (c *Checksums) compute(algorithm ChecksumAlgorithm, body []byte) error {
+	if algorithm == "" {
+		return nil
+	}
+
+	field := c.field(algorithm)
+	if field == nil {
+		return fmt.Errorf("client: unsupported checksum algorithm %q", algorithm)
+	}
+
+	if *field != "" {
+		return nil
+	}
+
+	checksum, err := computeChecksum(algorithm, body)
+	if err != nil {
+		return err
+	}
+
+	*field = checksum
+
+	return nil
+}
+
+// compositeChecksum returns the checksum of a multipart object computed by S3
+// for the COMPOSITE type: the checksum of the concatenated part checksums,
+// followed by the number of parts.
+func compositeChecksum(algorithm ChecksumAlgorithm, parts []CompletedPart) (string, error) {
+	h, err := algorithm.newHash()
+	if err != nil {
+		return "", err
+	}
+
+	for _, part := range parts {
+		encoded := part.Checksums.Get(algorithm)
+		if encoded == "" {
+			return "", fmt.Errorf("client: missing %s checksum of part %d", algorithm, part.PartNumber)
+		}
+
+		checksum, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return "", fmt.Errorf("client: invalid %s checksum of part %d: %w", algorithm, part.PartNumber, err)
+		}
+
+		h.Write(checksum)
+	}
+
+	return fmt.Sprintf("%s-%d", base64.StdEncoding.EncodeToString(h.Sum(nil)), len(parts)), nil
+}
+
+var checksumHeaders = []struct {
+	algorithm ChecksumAlgorithm
+	header    string
+}{
+	{algorithm: ChecksumAlgorithmCRC32, header: api.HeaderXAmzChecksumCrc32},
+	{algorithm: ChecksumAlgorithmCRC32C, header: api.HeaderXAmzChecksumCrc32c},
+	{algorithm: ChecksumAlgorithmCRC64NVME, header: api.HeaderXAmzChecksumCrc64nvme},
+	{algorithm: ChecksumAlgorithmSHA1, header: api.HeaderXAmzChecksumSHA1},
+	{algorithm: ChecksumAlgorithmSHA256, header: api.HeaderXAmzChecksumSHA256},
+}
+
+func (c *Checksums) setHeaders(header *fasthttp.RequestHeader) {
+	for _, checksum := range checksumHeaders {
+		if value := c.Get(checksum.algorithm); value != "" {
+			header.Set(checksum.header, value)
+		}
+	}
+}
+
+// orSent returns the checksums echoed by the server, or the sent ones when it
+// does not echo them. Not every server echoes them, the sent ones are still
+// valid since the request succeeded.
+func (c Checksums) orSent(sent Checksums) Checksums {
+	if c == (Checksums{}) {
+		return sent
+	}
+
+	return c
+}
+
+func checksumsFromHeaders(header *fasthttp.ResponseHeader) Checksums {
+	var ret Checksums
+
+	for _, checksum := range checksumHeaders {
+		*ret.field(checksum.algorithm) = string(header.Peek(checksum.header))
+	}
+
+	return ret
+}
+
+func checksumsFromAPI(checksums api.Checksums) Checksums {
+	return Checksums{
+		CRC32:     checksums.ChecksumCRC32,
+		CRC32C:    checksums.ChecksumCRC32C,
+		CRC64NVME: checksums.ChecksumCRC64NVME,
+		SHA1:      checksums.ChecksumSHA1,
+		SHA256:    checksums.ChecksumSHA256,
+	}
+}
+
+func (c *Checksums) toAPI() api.Checksums {
+	return api.Checksums{
+		ChecksumCRC32:     c.CRC32,
+		ChecksumCRC32C:    c.CRC32C,
+		ChecksumCRC64NVME: c.CRC64NVME,
+		ChecksumSHA1:      c.SHA1,
+		ChecksumSHA256:    c.SHA256,
+	}
+}
+
+// crcCombiner combines the CRCs of consecutive blocks into the CRC of their
+// concatenation, like crc32_combine of zlib. It works on reflected CRCs whose
+// initial and final values are equal, which is the case of every CRC
+// algorithm offered by S3.
+type crcCombiner struct {
+	// poly is the reversed polynomial.
+	poly  uint64
+	width int
+}
+
+func (a ChecksumAlgorithm) crcCombiner() (crcCombiner, bool) {
+	switch a {
+	case ChecksumAlgorithmCRC32:
+		return crcCombiner{poly: crc32.IEEE, width: 32}, true
+	case ChecksumAlgorithmCRC32C:
+		return crcCombiner{poly: crc32.Castagnoli, width: 32}, true
+	case ChecksumAlgorithmCRC64NVME:
+		return crcCombiner{poly: crc64NVME, width: 64}, true
+	default:
+		return crcCombiner{}, false
+	}
+}
+
+// combine returns the CRC of A followed by B from the CRC of A, the CRC of B
+// and the length of B in bytes. CRCs are big endian as sent by S3.
+func (c crcCombiner) combine(crcA, crcB []byte, lengthB int64) []byte {
+	a, b := c.decode(crcA), c.decode(crcB)
+
+	return c.encode(c.multModP(c.xPow8nModP(lengthB), a) ^ b)
+}
+
+// multModP multiplies two polynomials modulo the CRC polynomial, both being
+// reflected: x^0 is the most significant bit.
+func (c crcCombiner) multModP(a, b uint64) uint64 {
+	var p uint64
+
+	for m := uint64(1) << (c.width - 1); m != 0; m >>= 1 {
+		if a&m != 0 {
+			p ^= b
+		}
+
+		if b&1 != 0 {
+			b = b>>1 ^ c.poly
+		} else {
+			b >>= 1
+		}
+	}
+
+	return p
+}
+
+// xPow8nModP returns x^(8*n) modulo the CRC polynomial, shifting a CRC by n
+// zero bytes.
+func (c crcCombiner) xPow8nModP(n int64) uint64 {
+	result := uint64(1) << (c.width - 1) // x^0
+	square := uint64(1) << (c.width - 9) // x^8
+
+	for bits := uint64(n); bits != 0; bits >>= 1 {
+		if bits&1 != 0 {
+			result = c.multModP(result, square)
+		}
+
+		square = c.multModP(square, square)
+	}
+
+	return result
+}
+
+func (c crcCombiner) decode(crc []byte) uint64 {
+	if c.width == 32 { //nolint:mnd // CRC32 width
+		return uint64(binary.BigEndian.Uint32(crc))
+	}
+
+	return binary.BigEndian.Uint64(crc)
+}
+
+func (c crcCombiner) encode(crc uint64) []byte {
+	if c.width == 32 { //nolint:mnd // CRC32 width
+		return binary.BigEndian.AppendUint32(nil, uint32(crc)) //nolint:gosec // The CRC holds 32 bits
+	}
+
+	return binary.BigEndian.AppendUint64(nil, crc)
+}
+
+// fullObjectChecksum combines the CRCs of the sorted parts into the checksum
+// of the whole object.
+func fullObjectChecksum(algorithm ChecksumAlgorithm, parts []CompletedPart, partSize func(partNumber int) int64) (string, error) {
+	combiner, ok := algorithm.crcCombiner()
+	if !ok {
+		return "", fmt.Errorf("client: %s checksums cannot be combined", algorithm)
+	}
+
+	var crc []byte
+
+	for _, part := range parts {
+		checksum, err := base64.StdEncoding.DecodeString(part.Checksums.Get(algorithm))
+		if err != nil || len(checksum) != combiner.width/8 { //nolint:mnd // Bits per byte
+			return "", fmt.Errorf("client: invalid %s checksum of part %d", algorithm, part.PartNumber)
+		}
+
+		if crc == nil {
+			crc = checksum
+		} else {
+			crc = combiner.combine(crc, checksum, partSize(part.PartNumber))
+		}
+	}
+
+	return base64.StdEncoding.EncodeToString(crc), nil
+}
@@ -0,0 +1,81 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeChecksum(t *testing.T) {
+	// The check values of the CRC catalogue are computed over "123456789".
+	testCases := map[ChecksumAlgorithm]string{
+		ChecksumAlgorithmCRC32:     "y/Q5Jg==",
+		ChecksumAlgorithmCRC32C:    "4waSgw==",
+		ChecksumAlgorithmCRC64NVME: "rosUhgp5mIg=",
+		ChecksumAlgorithmSHA1:      "98O8HYCOBHMq32eZZczDTKeuNEE=",
+		ChecksumAlgorithmSHA256:    "FeKw08M4keuw8e9gnsQZQgwg4yDOlMZfvIwzEkSOsiU=",
+	}
+
+	for algorithm, expected := range testCases {
+		t.Run(string(algorithm), func(t *testing.T) {
+			actual, err := computeChecksum(algorithm, []byte("123456789"))
+			require.NoError(t, err)
+			assert.Equal(t, expected, actual)
+
+			var checksums Checksums
+			require.NoError(t, checksums.compute(algorithm, []byte("123456789")))
+			assert.Equal(t, expected, checksums.Get(algorithm))
+		})
+	}
+
+	t.Run("unsupported", func(t *testing.T) {
+		_, err := computeChecksum("MD5", nil)
+		require.EqualError(t, err, `client: unsupported checksum algorithm "MD5"`)
+	})
+
+	t.Run("provided checksum is kept", func(t *testing.T) {
+		checksums := Checksums{CRC32: "provided"}
+		require.NoError(t, checksums.compute(ChecksumAlgorithmCRC32, []byte("123456789")))
+		assert.Equal(t, "provided", checksums.CRC32)
+	})
+}
+
+func TestCRCCombiner(t *testing.T) {
+	data := []byte("The quick brown fox jumps over the lazy dog")
+
+	for _, algorithm := range []ChecksumAlgorithm{ChecksumAlgorithmCRC32, ChecksumAlgorithmCRC32C, ChecksumAlgorithmCRC64NVME} {
+		t.Run(string(algorithm), func(t *testing.T) {
+			combiner, ok := algorithm.crcCombiner()
+			require.True(t, ok)
+
+			expected := rawChecksum(t, algorithm, data)
+
+			for _, split := range []int{0, 1, 10, len(data) - 1, len(data)} {
+				actual := combiner.combine(rawChecksum(t, algorithm, data[:split]), rawChecksum(t, algorithm, data[split:]), int64(len(data)-split))
+				assert.Equal(t, expected, actual, "split at %d", split)
+			}
+		})
+	}
+
+	_, ok := ChecksumAlgorithmSHA256.crcCombiner()
+	assert.False(t, ok)
+}
+
+func rawChecksum(t *testing.T, algorithm ChecksumAlgorithm, data []byte) []byte {
+	t.Helper()
+
+	h, err := algorithm.newHash()
+	require.NoError(t, err)
+
+	h.Write(data)
+
+	return h.Sum(nil)
+}
+
+func TestChecksumsOrSent(t *testing.T) {
+	sent := Checksums{CRC32: "sOO8/Q=="}
+
+	assert.Equal(t, sent, Checksums{}.orSent(sent), "not echoed")
+	assert.Equal(t, Checksums{CRC32C: "AAAAAA=="}, Checksums{CRC32C: "AAAAAA=="}.orSent(sent), "echoed")
+}
@@ -2,43 +2,86 @@ package client
 
 import (
 	"errors"
-)
+	"net/url"
+	"time"
 
-type Client struct{}
+	"github.com/lvjp/s3hobby/pkg/s3/signing"
+	v4 "github.com/lvjp/s3hobby/pkg/s3/signing/v4"
 
-func New() (*Client, error) {
-	return nil, errors.ErrUnsupported
-}
+	"github.com/valyala/fasthttp"
+)
 
-func (*Client) AbortMultipartUpload() error {
-	return errors.ErrUnsupported
+// HTTPClient is the subset of fasthttp.Client used to send requests.
+type HTTPClient interface {
+	Do(req *fasthttp.Request, resp *fasthttp.Response) error
+	DoDeadline(req *fasthttp.Request, resp *fasthttp.Response, deadline time.Time) error
 }
 
-func (*Client) CompleteMultipartUpload() error {
-	return errors.ErrUnsupported
-}
+type Options struct {
+	Region      string
+	Credentials signing.Credentials
 
-func (*Client) CopyObject() error {
-	return errors.ErrUnsupported
-}
+	// Endpoint is the base URL of the S3 service, like "http://localhost:9000".
+	// Defaults to the AWS regional endpoint, directory buckets then use their
+	// zonal endpoint.
+	Endpoint string
 
-func (*Client) CreateBucket() error {
-	return errors.ErrUnsupported
-}
+	// UsePathStyle addresses buckets in the request path instead of the host name.
+	UsePathStyle bool
 
-func (*Client) CreateBucketMetadataTableConfiguration() error {
-	return errors.ErrUnsupported
+	// Signer defaults to v4.NewDynamicSigner().
+	Signer signing.Signer
+
+	// HTTPClient defaults to a fasthttp.Client with DisablePathNormalizing set.
+	// A fasthttp.Client or HostClient given here must set it as well, since
+	// they override the setting of each request.
+	HTTPClient HTTPClient
+
+	// Clock defaults to signing.SystemClock.
+	Clock signing.Clock
+
+	// ContentMD5 adds a signed Content-MD5 header to every request having a
+	// body, the ETag of single part uploads is then checked against it.
+	ContentMD5 bool
 }
 
-func (*Client) CreateMultipartUpload() error {
-	return errors.ErrUnsupported
+type Client struct {
+	options  Options
+	endpoint *url.URL
+
+	clockSkews clockSkews
+	sessions   sessionCache
 }
 
-func (*Client) CreateSession() error {
-	return errors.ErrUnsupported
+func New(options Options) (*Client, error) {
+	if options.Region == "" {
+		return nil, errors.New("client: region must be set")
+	}
+
+	if options.Signer == nil {
+		options.Signer = v4.NewDynamicSigner()
+	}
+
+	if options.HTTPClient == nil {
+		options.HTTPClient = &fasthttp.Client{DisablePathNormalizing: true}
+	}
+
+	if options.Clock == nil {
+		options.Clock = signing.SystemClock
+	}
+
+	endpoint, err := parseEndpoint(options.Endpoint, options.Region)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		options:  options,
+		endpoint: endpoint,
+	}, nil
 }
 
-func (*Client) DeleteBucket() error {
+func (*Client) CreateBucketMetadataTableConfiguration() error {
 	return errors.ErrUnsupported
 }
 
@@ -98,14 +141,6 @@ func (*Client) DeleteObject() error {
 	return errors.ErrUnsupported
 }
 
-func (*Client) DeleteObjects() error {
-	return errors.ErrUnsupported
-}
-
-func (*Client) DeleteObjectTagging() error {
-	return errors.ErrUnsupported
-}
-
 func (*Client) DeletePublicAccessBlock() error {
 	return errors.ErrUnsupported
 }
@@ -146,10 +181,6 @@ func (*Client) GetBucketLifecycleConfiguration() error {
 	return errors.ErrUnsupported
 }
 
-func (*Client) GetBucketLocation() error {
-	return errors.ErrUnsupported
-}
-
 func (*Client) GetBucketLogging() error {
 	return errors.ErrUnsupported
 }
@@ -202,10 +233,6 @@ func (*Client) GetBucketWebsite() error {
 	return errors.ErrUnsupported
 }
 
-func (*Client) GetObject() error {
-	return errors.ErrUnsupported
-}
-
 func (*Client) GetObjectAcl() error {
 	return errors.ErrUnsupported
 }
@@ -226,10 +253,6 @@ func (*Client) GetObjectRetention() error {
 	return errors.ErrUnsupported
 }
 
-func (*Client) GetObjectTagging() error {
-	return errors.ErrUnsupported
-}
-
 func (*Client) GetObjectTorrent() error {
 	return errors.ErrUnsupported
 }
@@ -238,14 +261,6 @@ func (*Client) GetPublicAccessBlock() error {
 	return errors.ErrUnsupported
 }
 
-func (*Client) HeadBucket() error {
-	return errors.ErrUnsupported
-}
-
-func (*Client) HeadObject() error {
-	return errors.ErrUnsupported
-}
-
 func (*Client) ListBucketAnalyticsConfigurations() error {
 	return errors.ErrUnsupported
 }
@@ -262,34 +277,14 @@ func (*Client) ListBucketMetricsConfigurations() error {
 	return errors.ErrUnsupported
 }
 
-func (*Client) ListBuckets() error {
-	return errors.ErrUnsupported
-}
-
 func (*Client) ListDirectoryBuckets() error {
 	return errors.ErrUnsupported
 }
 
-func (*Client) ListMultipartUploads() error {
-	return errors.ErrUnsupported
-}
-
-func (*Client) ListObjects() error {
-	return errors.ErrUnsupported
-}
-
-func (*Client) ListObjectsV2() error {
-	return errors.ErrUnsupported
-}
-
 func (*Client) ListObjectVersions() error {
 	return errors.ErrUnsupported
 }
 
-func (*Client) ListParts() error {
-	return errors.ErrUnsupported
-}
-
 func (*Client) PutBucketAccelerateConfiguration() error {
 	return errors.ErrUnsupported
 }
@@ -370,10 +365,6 @@ func (*Client) PutBucketWebsite() error {
 	return errors.ErrUnsupported
 }
 
-func (*Client) PutObject() error {
-	return errors.ErrUnsupported
-}
-
 func (*Client) PutObjectAcl() error {
 	return errors.ErrUnsupported
 }
@@ -390,10 +381,6 @@ func (*Client) PutObjectRetention() error {
 	return errors.ErrUnsupported
 }
 
-func (*Client) PutObjectTagging() error {
-	return errors.ErrUnsupported
-}
-
 func (*Client) PutPublicAccessBlock() error {
 	return errors.ErrUnsupported
 }
@@ -405,11 +392,3 @@ func (*Client) RestoreObject() error {
 func (*Client) SelectObjectContent() error {
 	return errors.ErrUnsupported
 }
-
-func (*Client) UploadPart() error {
-	return errors.ErrUnsupported
-}
-
-func (*Client) UploadPartCopy() error {
-	return errors.ErrUnsupported
-}
@@ -0,0 +1,119 @@
+package client
+
+import (
+	"cmp"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/lvjp/s3hobby/pkg/s3/api"
+
+	"github.com/valyala/fasthttp"
+)
+
+type CompletedPart struct {
+	PartNumber int
+	ETag       string
+	Checksums  Checksums
+}
+
+type CompleteMultipartUploadInput struct {
+	Bucket   string
+	Key      string
+	UploadID string
+
+	// Parts are sent sorted by part number, whatever their order.
+	Parts []CompletedPart
+
+	// Checksums of the whole object, checked by S3 when provided.
+	Checksums    Checksums
+	ChecksumType ChecksumType
+
+	// IfMatch and IfNoneMatch make the completion conditional, like the ones
+	// of PutObject.
+	IfMatch     string
+	IfNoneMatch string
+}
+
+type CompleteMultipartUploadOutput struct {
+	Location  string
+	Bucket    string
+	Key       string
+	ETag      string
+	VersionID string
+
+	Checksums    Checksums
+	ChecksumType ChecksumType
+}
+
+// CompleteMultipartUpload assembles the uploaded parts. S3 may report a
+// failure after having sent a 200 OK status, it is returned as *api.Error.
+func (c *Client) CompleteMultipartUpload(ctx context.Context, input *CompleteMultipartUploadInput) (*CompleteMultipartUploadOutput, error) {
+	if len(input.Parts) == 0 {
+		return nil, errors.New("CompleteMultipartUpload: at least one part is required")
+	}
+
+	parts := slices.SortedFunc(slices.Values(input.Parts), func(a, b CompletedPart) int {
+		return cmp.Compare(a.PartNumber, b.PartNumber)
+	})
+
+	document := api.CompleteMultipartUpload{
+		Parts: make([]api.CompletedPart, 0, len(parts)),
+	}
+
+	for _, part := range parts {
+		document.Parts = append(document.Parts, api.CompletedPart{
+			PartNumber: part.PartNumber,
+			ETag:       part.ETag,
+			Checksums:  part.Checksums.toAPI(),
+		})
+	}
+
+	body, err := xml.Marshal(document)
+	if err != nil {
+		return nil, fmt.Errorf("CompleteMultipartUpload: cannot marshal parts: %w", err)
+	}
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(resp)
+
+	if err := c.setRequestURI(req, input.Bucket, input.Key); err != nil {
+		return nil, err
+	}
+
+	req.Header.SetMethod(fasthttp.MethodPost)
+	req.URI().QueryArgs().Add("uploadId", input.UploadID)
+
+	input.Checksums.setHeaders(&req.Header)
+	setHeader(&req.Header, api.HeaderXAmzChecksumType, string(input.ChecksumType))
+	setHeader(&req.Header, api.HeaderIfMatch, input.IfMatch)
+	setHeader(&req.Header, api.HeaderIfNoneMatch, input.IfNoneMatch)
+	req.SetBodyRaw(body)
+
+	if err := c.doBucket(ctx, input.Bucket, req, resp); err != nil {
+		return nil, conditionalError(err)
+	}
+
+	if err := checkErrorBody(resp); err != nil {
+		return nil, conditionalError(err)
+	}
+
+	var result api.CompleteMultipartUploadResult
+	if err := xml.Unmarshal(resp.Body(), &result); err != nil {
+		return nil, fmt.Errorf("CompleteMultipartUpload: cannot parse response: %w", err)
+	}
+
+	return &CompleteMultipartUploadOutput{
+		Location:     result.Location,
+		Bucket:       result.Bucket,
+		Key:          result.Key,
+		ETag:         result.ETag,
+		VersionID:    string(resp.Header.Peek(api.HeaderXAmzVersionID)),
+		Checksums:    checksumsFromAPI(result.Checksums).orSent(input.Checksums),
+		ChecksumType: ChecksumType(result.ChecksumType),
+	}, nil
+}
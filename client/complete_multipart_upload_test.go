@@ -0,0 +1,248 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lvjp/s3hobby/pkg/s3/api"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func TestCreateMultipartUpload(t *testing.T) {
+	c, httpClient, _ := newTestClient(t, func(*fasthttp.Request) string {
+		return rawResponse(fasthttp.StatusOK, `<?xml version="1.0" encoding="UTF-8"?>
+<InitiateMultipartUploadResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+  <Bucket>examplebucket</Bucket>
+  <Key>example-object</Key>
+  <UploadId>VXBsb2FkIElEIGZvciA2aWWpbmcncyBteS1tb3ZpZS5tMnRzIHVwbG9hZA</UploadId>
+</InitiateMultipartUploadResult>`, "x-amz-checksum-algorithm: CRC32", "x-amz-checksum-type: FULL_OBJECT")
+	})
+
+	output, err := c.CreateMultipartUpload(context.Background(), &CreateMultipartUploadInput{
+		Bucket:            "examplebucket",
+		Key:               "example-object",
+		ContentType:       "video/mp2t",
+		Metadata:          map[string]string{"author": "me"},
+		ChecksumAlgorithm: ChecksumAlgorithmCRC32,
+		ChecksumType:      ChecksumTypeFullObject,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, &CreateMultipartUploadOutput{
+		Bucket:            "examplebucket",
+		Key:               "example-object",
+		UploadID:          "VXBsb2FkIElEIGZvciA2aWWpbmcncyBteS1tb3ZpZS5tMnRzIHVwbG9hZA",
+		ChecksumAlgorithm: ChecksumAlgorithmCRC32,
+		ChecksumType:      ChecksumTypeFullObject,
+	}, output)
+
+	require.Len(t, httpClient.requests, 1)
+	sent := httpClient.requests[0]
+	assert.Equal(t, fasthttp.MethodPost, string(sent.Header.Method()))
+	assert.Equal(t, "https://examplebucket.s3.us-east-1.amazonaws.com/example-object?uploads", sent.URI().String())
+	assert.Equal(t, "video/mp2t", string(sent.Header.Peek(api.HeaderContentType)))
+	assert.Equal(t, "me", string(sent.Header.Peek("x-amz-meta-author")))
+	assert.Equal(t, "CRC32", string(sent.Header.Peek(api.HeaderXAmzChecksumAlgorithm)))
+	assert.Equal(t, "FULL_OBJECT", string(sent.Header.Peek(api.HeaderXAmzChecksumType)))
+}
+
+func TestUploadPart(t *testing.T) {
+	t.Run("checksum", func(t *testing.T) {
+		c, httpClient, _ := newTestClient(t, func(*fasthttp.Request) string {
+			return rawResponse(fasthttp.StatusOK, "", `ETag: "25f9e794323b453885f5181f1b624d0b"`)
+		})
+
+		output, err := c.UploadPart(context.Background(), &UploadPartInput{
+			Bucket:            "examplebucket",
+			Key:               "example-object",
+			UploadID:          "UPLOAD",
+			PartNumber:        2,
+			Body:              []byte("123456789"),
+			ChecksumAlgorithm: ChecksumAlgorithmCRC32,
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, &UploadPartOutput{
+			PartNumber: 2,
+			ETag:       `"25f9e794323b453885f5181f1b624d0b"`,
+			Checksums:  Checksums{CRC32: "y/Q5Jg=="},
+		}, output)
+
+		assert.Equal(t, CompletedPart{
+			PartNumber: 2,
+			ETag:       `"25f9e794323b453885f5181f1b624d0b"`,
+			Checksums:  Checksums{CRC32: "y/Q5Jg=="},
+		}, output.CompletedPart())
+
+		require.Len(t, httpClient.requests, 1)
+		sent := httpClient.requests[0]
+		assert.Equal(t, fasthttp.MethodPut, string(sent.Header.Method()))
+		assert.Equal(t, "partNumber=2&uploadId=UPLOAD", sent.URI().QueryArgs().String())
+		assert.Equal(t, "y/Q5Jg==", string(sent.Header.Peek(api.HeaderXAmzChecksumCrc32)))
+		assert.Equal(t, "123456789", string(sent.Body()))
+	})
+
+	t.Run("invalid part number", func(t *testing.T) {
+		c, httpClient, _ := newTestClient(t, nil)
+
+		for _, partNumber := range []int{0, MaxPartNumber + 1} {
+			_, err := c.UploadPart(context.Background(), &UploadPartInput{
+				Bucket:     "examplebucket",
+				Key:        "example-object",
+				UploadID:   "UPLOAD",
+				PartNumber: partNumber,
+			})
+			require.EqualError(t, err, "UploadPart: part number must be between 1 and 10000")
+		}
+
+		assert.Empty(t, httpClient.requests)
+	})
+}
+
+func TestAbortMultipartUpload(t *testing.T) {
+	c, httpClient, _ := newTestClient(t, func(*fasthttp.Request) string {
+		return rawResponse(fasthttp.StatusNoContent, "")
+	})
+
+	_, err := c.AbortMultipartUpload(context.Background(), &AbortMultipartUploadInput{
+		Bucket:   "examplebucket",
+		Key:      "example-object",
+		UploadID: "UPLOAD",
+	})
+	require.NoError(t, err)
+
+	require.Len(t, httpClient.requests, 1)
+	sent := httpClient.requests[0]
+	assert.Equal(t, fasthttp.MethodDelete, string(sent.Header.Method()))
+	assert.Equal(t, "uploadId=UPLOAD", sent.URI().QueryArgs().String())
+}
+
+func TestCompleteMultipartUpload(t *testing.T) {
+	input := &CompleteMultipartUploadInput{
+		Bucket:   "examplebucket",
+		Key:      "example-object",
+		UploadID: "UPLOAD",
+		Parts: []CompletedPart{
+			{PartNumber: 2, ETag: `"7778aef83f66abc1fa1e8477f296d394"`, Checksums: Checksums{CRC32: "part2"}},
+			{PartNumber: 1, ETag: `"a54357aff0632cce46d942af68356b38"`, Checksums: Checksums{CRC32: "part1"}},
+		},
+		Checksums:    Checksums{CRC32: "object"},
+		ChecksumType: ChecksumTypeFullObject,
+	}
+
+	t.Run("success", func(t *testing.T) {
+		c, httpClient, _ := newTestClient(t, func(*fasthttp.Request) string {
+			return rawResponse(fasthttp.StatusOK, `<?xml version="1.0" encoding="UTF-8"?>
+<CompleteMultipartUploadResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+  <Location>https://examplebucket.s3.us-east-1.amazonaws.com/example-object</Location>
+  <Bucket>examplebucket</Bucket>
+  <Key>example-object</Key>
+  <ETag>"3858f62230ac3c915f300c664312c11f-2"</ETag>
+  <ChecksumCRC32>object</ChecksumCRC32>
+  <ChecksumType>FULL_OBJECT</ChecksumType>
+</CompleteMultipartUploadResult>`, "x-amz-version-id: 3/L4kqtJlcpXroDTDmJ+rmSpXd3dIbrHY+MTRCxf3vjVBH40Nr8X8gdRQBpUMLUo")
+		})
+
+		output, err := c.CompleteMultipartUpload(context.Background(), input)
+		require.NoError(t, err)
+
+		assert.Equal(t, &CompleteMultipartUploadOutput{
+			Location:     "https://examplebucket.s3.us-east-1.amazonaws.com/example-object",
+			Bucket:       "examplebucket",
+			Key:          "example-object",
+			ETag:         `"3858f62230ac3c915f300c664312c11f-2"`,
+			VersionID:    "3/L4kqtJlcpXroDTDmJ+rmSpXd3dIbrHY+MTRCxf3vjVBH40Nr8X8gdRQBpUMLUo",
+			Checksums:    Checksums{CRC32: "object"},
+			ChecksumType: ChecksumTypeFullObject,
+		}, output)
+
+		require.Len(t, httpClient.requests, 1)
+		sent := httpClient.requests[0]
+		assert.Equal(t, fasthttp.MethodPost, string(sent.Header.Method()))
+		assert.Equal(t, "uploadId=UPLOAD", sent.URI().QueryArgs().String())
+		assert.Equal(t, "object", string(sent.Header.Peek(api.HeaderXAmzChecksumCrc32)))
+		assert.Equal(t, "FULL_OBJECT", string(sent.Header.Peek(api.HeaderXAmzChecksumType)))
+		assert.Equal(t, `<CompleteMultipartUpload xmlns="http://s3.amazonaws.com/doc/2006-03-01/">`+
+			`<Part><PartNumber>1</PartNumber><ETag>&#34;a54357aff0632cce46d942af68356b38&#34;</ETag><ChecksumCRC32>part1</ChecksumCRC32></Part>`+
+			`<Part><PartNumber>2</PartNumber><ETag>&#34;7778aef83f66abc1fa1e8477f296d394&#34;</ETag><ChecksumCRC32>part2</ChecksumCRC32></Part>`+
+			`</CompleteMultipartUpload>`, string(sent.Body()))
+		assert.Equal(t, 2, input.Parts[0].PartNumber, "input must not be modified")
+	})
+
+	t.Run("error with 200 OK", func(t *testing.T) {
+		c, _, _ := newTestClient(t, func(*fasthttp.Request) string {
+			return rawResponse(fasthttp.StatusOK, `<?xml version="1.0" encoding="UTF-8"?>
+
+<Error>
+  <Code>InternalError</Code>
+  <Message>We encountered an internal error. Please try again.</Message>
+</Error>`)
+		})
+
+		_, err := c.CompleteMultipartUpload(context.Background(), input)
+
+		var apiErr *api.Error
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, fasthttp.StatusOK, apiErr.StatusCode)
+		assert.Equal(t, "InternalError", apiErr.Code)
+	})
+
+	t.Run("conditional", func(t *testing.T) {
+		conditional := *input
+		conditional.IfNoneMatch = "*"
+
+		testCases := []struct {
+			name     string
+			response string
+			expected error
+		}{
+			{
+				name:     "precondition failed",
+				response: rawResponse(fasthttp.StatusPreconditionFailed, "<Error><Code>PreconditionFailed</Code></Error>"),
+				expected: ErrPreconditionFailed,
+			},
+			{
+				name:     "precondition failed with 200 OK",
+				response: rawResponse(fasthttp.StatusOK, "<Error><Code>PreconditionFailed</Code></Error>"),
+				expected: ErrPreconditionFailed,
+			},
+			{
+				name:     "conflict",
+				response: rawResponse(fasthttp.StatusConflict, "<Error><Code>ConditionalRequestConflict</Code></Error>"),
+				expected: ErrConditionalRequestConflict,
+			},
+		}
+
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				c, httpClient, _ := newTestClient(t, func(*fasthttp.Request) string {
+					return tc.response
+				})
+
+				_, err := c.CompleteMultipartUpload(context.Background(), &conditional)
+				require.ErrorIs(t, err, tc.expected)
+
+				var apiErr *api.Error
+				require.ErrorAs(t, err, &apiErr)
+
+				require.Len(t, httpClient.requests, 1)
+				assert.Equal(t, "*", string(httpClient.requests[0].Header.Peek(api.HeaderIfNoneMatch)))
+			})
+		}
+	})
+
+	t.Run("no part", func(t *testing.T) {
+		c, httpClient, _ := newTestClient(t, nil)
+
+		_, err := c.CompleteMultipartUpload(context.Background(), &CompleteMultipartUploadInput{
+			Bucket:   "examplebucket",
+			Key:      "example-object",
+			UploadID: "UPLOAD",
+		})
+		require.EqualError(t, err, "CompleteMultipartUpload: at least one part is required")
+		assert.Empty(t, httpClient.requests)
+	})
+}
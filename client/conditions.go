@@ -0,0 +1,38 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lvjp/s3hobby/pkg/s3/api"
+
+	"github.com/valyala/fasthttp"
+)
+
+var (
+	// ErrPreconditionFailed is returned, along the *api.Error, when a
+	// condition like If-Match is not met.
+	ErrPreconditionFailed = errors.New("client: precondition failed")
+
+	// ErrConditionalRequestConflict is returned, along the *api.Error, when a
+	// conditional write conflicts with a concurrent one. It may be retried.
+	ErrConditionalRequestConflict = errors.New("client: conditional request conflict")
+)
+
+// conditionalError marks the errors caused by the request conditions, the
+// other ones are returned unchanged.
+func conditionalError(err error) error {
+	var apiErr *api.Error
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+
+	switch {
+	case apiErr.StatusCode == fasthttp.StatusPreconditionFailed, apiErr.Code == api.ErrorCodePreconditionFailed:
+		return fmt.Errorf("%w: %w", ErrPreconditionFailed, err)
+	case apiErr.Code == api.ErrorCodeConditionalRequestConflict:
+		return fmt.Errorf("%w: %w", ErrConditionalRequestConflict, err)
+	default:
+		return err
+	}
+}
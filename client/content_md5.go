@@ -0,0 +1,51 @@
+package client
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lvjp/s3hobby/pkg/s3/api"
+
+	"github.com/valyala/fasthttp"
+)
+
+// ErrContentMD5Mismatch is returned when the ETag of an upload is not the MD5
+// digest of the sent body.
+var ErrContentMD5Mismatch = errors.New("client: ETag does not match Content-MD5")
+
+// checkETag verifies the ETag of single part uploads against the Content-MD5
+// of the request. The ETag is only the MD5 digest of the object for plain PUT
+// requests stored without SSE-KMS nor SSE-C, other requests are ignored.
+func checkETag(req *fasthttp.Request, resp *fasthttp.Response) error {
+	contentMD5 := req.Header.Peek(api.HeaderContentMD5)
+
+	switch {
+	case len(contentMD5) == 0,
+		!req.Header.IsPut(),
+		req.URI().QueryArgs().Has("uploadId"),
+		len(req.Header.Peek(api.HeaderXAmzCopySource)) > 0,
+		strings.HasPrefix(string(resp.Header.Peek(api.HeaderXAmzServerSideEncryption)), "aws:kms"),
+		len(resp.Header.Peek(api.HeaderXAmzServerSideEncryptionCustomerAlgorithm)) > 0:
+		return nil
+	}
+
+	etag := strings.Trim(string(resp.Header.Peek(api.HeaderETag)), `"`)
+	if etag == "" || strings.Contains(etag, "-") {
+		// Missing or multipart ETag, nothing to compare with.
+		return nil
+	}
+
+	digest, err := base64.StdEncoding.DecodeString(string(contentMD5))
+	if err != nil {
+		return fmt.Errorf("client: invalid Content-MD5 %q: %w", contentMD5, err)
+	}
+
+	if expected := hex.EncodeToString(digest); !strings.EqualFold(etag, expected) {
+		return fmt.Errorf("%w: expected %q, got %q", ErrContentMD5Mismatch, expected, etag)
+	}
+
+	return nil
+}
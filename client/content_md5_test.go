@@ -0,0 +1,96 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lvjp/s3hobby/pkg/s3/api"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func TestContentMD5(t *testing.T) {
+	const helloETag = `ETag: "5d41402abc4b2a76b9719d911017c592"`
+	const otherETag = `ETag: "0123456789abcdef0123456789abcdef"`
+
+	testCases := []struct {
+		name       string
+		disabled   bool
+		uri        string
+		respHeader []string
+
+		expected error
+	}{
+		{
+			name:       "matching etag",
+			respHeader: []string{helloETag},
+		},
+		{
+			name:       "mismatching etag",
+			respHeader: []string{otherETag},
+			expected:   ErrContentMD5Mismatch,
+		},
+		{
+			name:       "disabled",
+			disabled:   true,
+			respHeader: []string{otherETag},
+		},
+		{
+			name:       "sse-kms",
+			respHeader: []string{otherETag, "X-Amz-Server-Side-Encryption: aws:kms"},
+		},
+		{
+			name:       "sse-c",
+			respHeader: []string{otherETag, "X-Amz-Server-Side-Encryption-Customer-Algorithm: AES256"},
+		},
+		{
+			name:       "multipart etag",
+			respHeader: []string{`ETag: "0123456789abcdef0123456789abcdef-2"`},
+		},
+		{
+			name:       "part upload",
+			uri:        "https://examplebucket.s3.amazonaws.com/photos/photo1.jpg?partNumber=1&uploadId=abc",
+			respHeader: []string{otherETag},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, httpClient, _ := newTestClient(t, func(*fasthttp.Request) string {
+				return rawResponse(fasthttp.StatusOK, "", tc.respHeader...)
+			})
+			c.options.ContentMD5 = !tc.disabled
+
+			uri := tc.uri
+			if uri == "" {
+				uri = "https://examplebucket.s3.amazonaws.com/photos/photo1.jpg"
+			}
+
+			var req fasthttp.Request
+			req.Header.SetMethod(fasthttp.MethodPut)
+			req.SetRequestURI(uri)
+			req.SetBodyString("hello")
+
+			var resp fasthttp.Response
+
+			err := c.Do(context.Background(), &req, &resp)
+			if tc.expected != nil {
+				require.ErrorIs(t, err, tc.expected)
+			} else {
+				require.NoError(t, err)
+			}
+
+			require.Len(t, httpClient.requests, 1)
+			sent := httpClient.requests[0]
+
+			if tc.disabled {
+				assert.Empty(t, sent.Header.Peek(api.HeaderContentMD5))
+			} else {
+				assert.Equal(t, "XUFAKrxLKna5cZ2REBfFkg==", string(sent.Header.Peek(api.HeaderContentMD5)))
+				assert.Contains(t, string(sent.Header.Peek(api.HeaderAuthorization)), api.HeaderContentMD5)
+			}
+		})
+	}
+}
@@ -0,0 +1,284 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+const (
+	DefaultCopyPartSize    int64 = 128 << 20
+	DefaultCopyConcurrency       = 5
+)
+
+type CopierOptions struct {
+	// PartSize is the size of the parts of multipart copies, it defaults to
+	// DefaultCopyPartSize. It is raised when needed to stay within
+	// MaxPartNumber parts.
+	PartSize int64
+
+	// Concurrency is the number of parts copied in parallel, it defaults to
+	// DefaultCopyConcurrency.
+	Concurrency int
+}
+
+// Copier copies objects of any size, using a multipart copy for objects larger
+// than MaxPartSize. It is safe for concurrent use.
+type Copier struct {
+	client  *Client
+	options CopierOptions
+}
+
+func NewCopier(client *Client, options CopierOptions) (*Copier, error) {
+	if options.PartSize == 0 {
+		options.PartSize = DefaultCopyPartSize
+	}
+
+	if options.PartSize < MinPartSize || options.PartSize > MaxPartSize {
+		return nil, fmt.Errorf("Copier: part size must be between %d and %d bytes", MinPartSize, MaxPartSize)
+	}
+
+	if options.Concurrency == 0 {
+		options.Concurrency = DefaultCopyConcurrency
+	}
+
+	if options.Concurrency < 1 {
+		return nil, errors.New("Copier: concurrency must be positive")
+	}
+
+	return &Copier{
+		client:  client,
+		options: options,
+	}, nil
+}
+
+type CopyInput struct {
+	Bucket string
+	Key    string
+	Source CopySource
+
+	// MetadataDirective defaults to MetadataDirectiveCopy, ContentType and
+	// Metadata are only used with MetadataDirectiveReplace.
+	MetadataDirective MetadataDirective
+	ContentType       string
+	Metadata          map[string]string
+
+	// TaggingDirective defaults to TaggingDirectiveCopy, Tagging is only used
+	// with TaggingDirectiveReplace.
+	TaggingDirective TaggingDirective
+	Tagging          TagSet
+
+	StorageClass      string
+	ChecksumAlgorithm ChecksumAlgorithm
+}
+
+type CopyOutput struct {
+	ETag      string
+	VersionID string
+
+	// UploadID and PartCount are only set by multipart copies.
+	UploadID  string
+	PartCount int
+
+	Checksums    Checksums
+	ChecksumType ChecksumType
+}
+
+// Copy reads the source attributes with HeadObject, then copies it with
+// CopyObject or, above MaxPartSize, with a multipart copy whose parts are
+// copied concurrently. The parts are pinned to the ETag of the source so that
+// the copy fails with ErrPreconditionFailed when the source is overwritten
+// meanwhile, and the metadata and tags of the source are preserved unless
+// replaced.
+//
+// On failure or context cancellation, the multipart upload is aborted and a
+// *MultipartUploadError is returned.
+func (cp *Copier) Copy(ctx context.Context, input *CopyInput) (*CopyOutput, error) {
+	head, err := cp.client.HeadObject(ctx, &HeadObjectInput{
+		Bucket:         input.Source.Bucket,
+		Key:            input.Source.Key,
+		VersionID:      input.Source.VersionID,
+		SSECustomerKey: input.Source.SSECustomerKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if head.ContentLength <= MaxPartSize {
+		return cp.copyObject(ctx, input)
+	}
+
+	createInput := &CreateMultipartUploadInput{
+		Bucket:            input.Bucket,
+		Key:               input.Key,
+		StorageClass:      input.StorageClass,
+		ChecksumAlgorithm: input.ChecksumAlgorithm,
+	}
+
+	if input.MetadataDirective == MetadataDirectiveReplace {
+		createInput.ContentType = input.ContentType
+		createInput.Metadata = input.Metadata
+	} else {
+		// Like CopyObject, the content headers are copied along the metadata.
+		createInput.ContentType = head.ContentType
+		createInput.Metadata = head.Metadata
+		createInput.CacheControl = head.CacheControl
+		createInput.ContentDisposition = head.ContentDisposition
+		createInput.ContentEncoding = head.ContentEncoding
+		createInput.ContentLanguage = head.ContentLanguage
+		createInput.Expires = head.Expires
+	}
+
+	if createInput.Tagging, err = cp.tagging(ctx, input); err != nil {
+		return nil, err
+	}
+
+	created, err := cp.client.CreateMultipartUpload(ctx, createInput)
+	if err != nil {
+		return nil, err
+	}
+
+	source := input.Source
+	if source.IfMatch == "" {
+		source.IfMatch = head.ETag
+	}
+
+	partSize := max(cp.options.PartSize, (head.ContentLength+MaxPartNumber-1)/MaxPartNumber)
+
+	upload := &multipartCopy{
+		copier:   cp,
+		input:    input,
+		source:   source,
+		uploadID: created.UploadID,
+		size:     head.ContentLength,
+		partSize: partSize,
+		parts:    make([]CompletedPart, (head.ContentLength+partSize-1)/partSize),
+	}
+
+	output, err := upload.run(ctx)
+	if err != nil {
+		return nil, cp.client.abortMultipartUpload(ctx, "Copier", input.Bucket, input.Key, created.UploadID, err)
+	}
+
+	return output, nil
+}
+
+func (cp *Copier) copyObject(ctx context.Context, input *CopyInput) (*CopyOutput, error) {
+	output, err := cp.client.CopyObject(ctx, &CopyObjectInput{
+		Bucket:            input.Bucket,
+		Key:               input.Key,
+		Source:            input.Source,
+		MetadataDirective: input.MetadataDirective,
+		ContentType:       input.ContentType,
+		Metadata:          input.Metadata,
+		TaggingDirective:  input.TaggingDirective,
+		Tagging:           input.Tagging,
+		StorageClass:      input.StorageClass,
+		ChecksumAlgorithm: input.ChecksumAlgorithm,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &CopyOutput{
+		ETag:         output.ETag,
+		VersionID:    output.VersionID,
+		Checksums:    output.Checksums,
+		ChecksumType: output.ChecksumType,
+	}, nil
+}
+
+// tagging returns the tags of a multipart copy, which cannot copy the ones of
+// the source by itself.
+func (cp *Copier) tagging(ctx context.Context, input *CopyInput) (TagSet, error) {
+	if input.TaggingDirective == TaggingDirectiveReplace {
+		return input.Tagging, nil
+	}
+
+	output, err := cp.client.GetObjectTagging(ctx, &GetObjectTaggingInput{
+		Bucket:    input.Source.Bucket,
+		Key:       input.Source.Key,
+		VersionID: input.Source.VersionID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return output.TagSet, nil
+}
+
+// multipartCopy holds the state of a multipart Copy.
+type multipartCopy struct {
+	copier   *Copier
+	input    *CopyInput
+	source   CopySource
+	uploadID string
+
+	size     int64
+	partSize int64
+
+	// Every part is only written by the goroutine copying it.
+	parts []CompletedPart
+}
+
+func (m *multipartCopy) run(ctx context.Context) (*CopyOutput, error) {
+	if err := m.copyParts(ctx); err != nil {
+		return nil, err
+	}
+
+	output, err := m.copier.client.CompleteMultipartUpload(ctx, &CompleteMultipartUploadInput{
+		Bucket:   m.input.Bucket,
+		Key:      m.input.Key,
+		UploadID: m.uploadID,
+		Parts:    m.parts,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &CopyOutput{
+		ETag:         output.ETag,
+		VersionID:    output.VersionID,
+		UploadID:     m.uploadID,
+		PartCount:    len(m.parts),
+		Checksums:    output.Checksums,
+		ChecksumType: output.ChecksumType,
+	}, nil
+}
+
+func (m *multipartCopy) copyParts(ctx context.Context) error {
+	group := newTaskGroup(ctx, m.copier.options.Concurrency)
+
+	for index := range m.parts {
+		if !group.Acquire() {
+			break
+		}
+
+		group.Go(func(ctx context.Context) error {
+			return m.copyPart(ctx, index)
+		})
+	}
+
+	return group.Wait()
+}
+
+func (m *multipartCopy) copyPart(ctx context.Context, index int) error {
+	start := int64(index) * m.partSize
+	end := min(start+m.partSize, m.size)
+
+	output, err := m.copier.client.UploadPartCopy(ctx, &UploadPartCopyInput{
+		Bucket:     m.input.Bucket,
+		Key:        m.input.Key,
+		UploadID:   m.uploadID,
+		PartNumber: index + 1,
+		Source:     m.source,
+		Range:      fmt.Sprintf("bytes=%d-%d", start, end-1),
+	})
+	if err != nil {
+		return fmt.Errorf("part %d: %w", index+1, err)
+	}
+
+	m.parts[index] = output.CompletedPart()
+
+	return nil
+}
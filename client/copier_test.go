@@ -0,0 +1,236 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/lvjp/s3hobby/pkg/s3/api"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+// newCopyServer returns a server serving the attributes of a source of the
+// size and accepting its copies. failPart, unless zero, answers the copy of
+// this part with an internal error. The copies pinned to another ETag than the
+// one of the source fail with a 412 status.
+func newCopyServer(t *testing.T, size int64, failPart int) *fakeServer {
+	t.Helper()
+
+	server := newFakeServer(t)
+	server.on(fasthttp.MethodHead, "", func(*fasthttp.Request) string {
+		return rawResponse(fasthttp.StatusOK, "",
+			fmt.Sprintf("Content-Length: %d", size),
+			`ETag: "source"`,
+			"Content-Type: video/mp4",
+			"Cache-Control: max-age=3600",
+			`Content-Disposition: attachment; filename="movie.mp4"`,
+			"Content-Encoding: identity",
+			"Content-Language: fr",
+			"Expires: Sun, 05 Aug 1984 13:50:00 GMT",
+			"x-amz-meta-author: me")
+	})
+	server.on(fasthttp.MethodGet, "tagging", func(*fasthttp.Request) string {
+		return rawResponse(fasthttp.StatusOK, `<Tagging xmlns="http://s3.amazonaws.com/doc/2006-03-01/"><TagSet><Tag><Key>project</Key><Value>hobby s3</Value></Tag></TagSet></Tagging>`)
+	})
+	server.on(fasthttp.MethodPost, "uploads", func(*fasthttp.Request) string {
+		return rawResponse(fasthttp.StatusOK, "<InitiateMultipartUploadResult><UploadId>UPLOAD</UploadId></InitiateMultipartUploadResult>")
+	})
+	stale := func(req *fasthttp.Request) bool {
+		ifMatch := req.Header.Peek(api.HeaderXAmzCopySourceIfMatch)
+		return len(ifMatch) > 0 && string(ifMatch) != `"source"`
+	}
+
+	server.on(fasthttp.MethodPut, "partNumber", func(req *fasthttp.Request) string {
+		partNumber, _ := strconv.Atoi(string(req.URI().QueryArgs().Peek("partNumber")))
+		if stale(req) {
+			return rawResponse(fasthttp.StatusPreconditionFailed, "<Error><Code>PreconditionFailed</Code></Error>")
+		}
+
+		if partNumber == failPart {
+			return rawResponse(fasthttp.StatusInternalServerError, "<Error><Code>InternalError</Code></Error>")
+		}
+
+		return rawResponse(fasthttp.StatusOK, fmt.Sprintf("<CopyPartResult><ETag>&quot;etag%d&quot;</ETag></CopyPartResult>", partNumber))
+	})
+	server.on(fasthttp.MethodPut, "", func(req *fasthttp.Request) string {
+		if stale(req) {
+			return rawResponse(fasthttp.StatusPreconditionFailed, "<Error><Code>PreconditionFailed</Code></Error>")
+		}
+
+		return rawResponse(fasthttp.StatusOK, "<CopyObjectResult><ETag>&quot;copy&quot;</ETag></CopyObjectResult>")
+	})
+	server.on(fasthttp.MethodPost, "uploadId", func(*fasthttp.Request) string {
+		return rawResponse(fasthttp.StatusOK, "<CompleteMultipartUploadResult><ETag>&quot;multipart-6&quot;</ETag></CompleteMultipartUploadResult>",
+			"x-amz-version-id: VERSION")
+	})
+	server.on(fasthttp.MethodDelete, "uploadId", func(*fasthttp.Request) string {
+		return rawResponse(fasthttp.StatusNoContent, "")
+	})
+
+	return server
+}
+
+// createdHeader returns the header of the single CreateMultipartUpload request.
+func createdHeader(t *testing.T, server *fakeServer) *fasthttp.RequestHeader {
+	t.Helper()
+
+	created := server.requests(fasthttp.MethodPost, "uploads")
+	require.Len(t, created, 1)
+
+	return &created[0].Header
+}
+
+// partHeaders maps the part numbers of the requests to their header value.
+func partHeaders(parts []*fasthttp.Request, key string) map[int]string {
+	ret := make(map[int]string, len(parts))
+
+	for _, req := range parts {
+		partNumber, _ := strconv.Atoi(string(req.URI().QueryArgs().Peek("partNumber")))
+		ret[partNumber] = string(req.Header.Peek(key))
+	}
+
+	return ret
+}
+
+var testCopierOptions = CopierOptions{PartSize: 1 << 30, Concurrency: 2}
+
+func TestCopier(t *testing.T) {
+	input := &CopyInput{
+		Bucket: "examplebucket",
+		Key:    "copy",
+		Source: CopySource{Bucket: "sourcebucket", Key: "example"},
+	}
+
+	t.Run("single request", func(t *testing.T) {
+		server := newCopyServer(t, MaxPartSize, 0)
+		copier := newTestHelper(t, server, NewCopier, testCopierOptions)
+
+		output, err := copier.Copy(context.Background(), input)
+		require.NoError(t, err)
+
+		assert.Equal(t, &CopyOutput{ETag: `"copy"`}, output)
+		assert.Len(t, server.requests(fasthttp.MethodPut, ""), 1)
+		assert.Empty(t, server.requests(fasthttp.MethodPost, "uploads"))
+	})
+
+	t.Run("multipart", func(t *testing.T) {
+		server := newCopyServer(t, MaxPartSize+1, 0)
+		copier := newTestHelper(t, server, NewCopier, testCopierOptions)
+
+		output, err := copier.Copy(context.Background(), input)
+		require.NoError(t, err)
+
+		assert.Equal(t, &CopyOutput{
+			ETag:      `"multipart-6"`,
+			VersionID: "VERSION",
+			UploadID:  "UPLOAD",
+			PartCount: 6,
+		}, output)
+
+		parts := server.requests(fasthttp.MethodPut, "partNumber")
+		assert.Len(t, server.requests(fasthttp.MethodPut, ""), len(parts), "the object must not be copied in a single request")
+
+		created := createdHeader(t, server)
+		assert.Equal(t, "video/mp4", string(created.ContentType()))
+		assert.Equal(t, "me", string(created.Peek("x-amz-meta-author")))
+		assert.Equal(t, "max-age=3600", string(created.Peek(api.HeaderCacheControl)))
+		assert.Equal(t, `attachment; filename="movie.mp4"`, string(created.Peek(api.HeaderContentDisposition)))
+		assert.Equal(t, "identity", string(created.Peek(api.HeaderContentEncoding)))
+		assert.Equal(t, "fr", string(created.Peek(api.HeaderContentLanguage)))
+		assert.Equal(t, "Sun, 05 Aug 1984 13:50:00 GMT", string(created.Peek(api.HeaderExpires)))
+		assert.Equal(t, "project=hobby+s3", string(created.Peek(api.HeaderXAmzTagging)))
+
+		assert.Equal(t, map[int]string{
+			1: "bytes=0-1073741823",
+			2: "bytes=1073741824-2147483647",
+			3: "bytes=2147483648-3221225471",
+			4: "bytes=3221225472-4294967295",
+			5: "bytes=4294967296-5368709119",
+			6: "bytes=5368709120-5368709120",
+		}, partHeaders(parts, api.HeaderXAmzCopySourceRange))
+
+		for partNumber, ifMatch := range partHeaders(parts, api.HeaderXAmzCopySourceIfMatch) {
+			assert.Equal(t, `"source"`, ifMatch, "part %d must be pinned to the source ETag", partNumber)
+		}
+
+		complete := server.requests(fasthttp.MethodPost, "uploadId")
+		require.Len(t, complete, 1)
+		assert.Contains(t, string(complete[0].Body()), `<Part><PartNumber>6</PartNumber><ETag>&#34;etag6&#34;</ETag></Part>`)
+		assert.Empty(t, server.requests(fasthttp.MethodDelete, "uploadId"))
+	})
+
+	t.Run("replaced metadata and tags", func(t *testing.T) {
+		server := newCopyServer(t, MaxPartSize+1, 0)
+		copier := newTestHelper(t, server, NewCopier, testCopierOptions)
+
+		_, err := copier.Copy(context.Background(), &CopyInput{
+			Bucket:            "examplebucket",
+			Key:               "copy",
+			Source:            CopySource{Bucket: "sourcebucket", Key: "example"},
+			MetadataDirective: MetadataDirectiveReplace,
+			ContentType:       "video/mp2t",
+			TaggingDirective:  TaggingDirectiveReplace,
+			Tagging:           TagSet{{Key: "copy", Value: "true"}},
+		})
+		require.NoError(t, err)
+
+		created := createdHeader(t, server)
+		assert.Equal(t, "video/mp2t", string(created.ContentType()))
+		assert.Empty(t, created.Peek("x-amz-meta-author"))
+		assert.Empty(t, created.Peek(api.HeaderCacheControl))
+		assert.Equal(t, "copy=true", string(created.Peek(api.HeaderXAmzTagging)))
+	})
+
+	t.Run("aborted", func(t *testing.T) {
+		server := newCopyServer(t, MaxPartSize+1, 3)
+		copier := newTestHelper(t, server, NewCopier, testCopierOptions)
+
+		_, err := copier.Copy(context.Background(), input)
+		require.EqualError(t, err, "Copier: upload UPLOAD failed: part 3: s3: 500 InternalError")
+
+		var uploadErr *MultipartUploadError
+		require.ErrorAs(t, err, &uploadErr)
+		assert.Equal(t, "Copier", uploadErr.Operation)
+		assert.NoError(t, uploadErr.AbortErr)
+		assert.Len(t, server.requests(fasthttp.MethodDelete, "uploadId"), 1)
+		assert.Empty(t, server.requests(fasthttp.MethodPost, "uploadId"))
+	})
+
+	t.Run("source changed", func(t *testing.T) {
+		changed := &CopyInput{
+			Bucket: "examplebucket",
+			Key:    "copy",
+			Source: CopySource{Bucket: "sourcebucket", Key: "example", IfMatch: `"before"`},
+		}
+
+		for _, size := range []int64{MaxPartSize, MaxPartSize + 1} {
+			server := newCopyServer(t, size, 0)
+			copier := newTestHelper(t, server, NewCopier, testCopierOptions)
+
+			_, err := copier.Copy(context.Background(), changed)
+			require.ErrorIs(t, err, ErrPreconditionFailed, "size %d", size)
+
+			var apiErr *api.Error
+			require.ErrorAs(t, err, &apiErr, "size %d", size)
+			assert.Equal(t, api.ErrorCodePreconditionFailed, apiErr.Code, "size %d", size)
+		}
+	})
+}
+
+func TestNewCopier(t *testing.T) {
+	c, _, _ := newTestClient(t, nil)
+
+	copier, err := NewCopier(c, CopierOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, CopierOptions{PartSize: DefaultCopyPartSize, Concurrency: DefaultCopyConcurrency}, copier.options)
+
+	_, err = NewCopier(c, CopierOptions{PartSize: MinPartSize - 1})
+	require.EqualError(t, err, "Copier: part size must be between 5242880 and 5368709120 bytes")
+
+	_, err = NewCopier(c, CopierOptions{Concurrency: -1})
+	require.EqualError(t, err, "Copier: concurrency must be positive")
+}
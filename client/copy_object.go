@@ -0,0 +1,182 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/lvjp/s3hobby/pkg/s3/api"
+	"github.com/lvjp/s3hobby/pkg/s3/signing"
+	"github.com/lvjp/s3hobby/pkg/s3/signing/functions"
+
+	"github.com/valyala/fasthttp"
+)
+
+// sseCustomerAlgorithm is the only algorithm supported by SSE-C.
+const sseCustomerAlgorithm = "AES256"
+
+// MetadataDirective tells whether CopyObject copies the metadata of the source
+// or replaces it with the one of the request.
+type MetadataDirective string
+
+const (
+	MetadataDirectiveCopy    MetadataDirective = "COPY"
+	MetadataDirectiveReplace MetadataDirective = "REPLACE"
+)
+
+// TaggingDirective tells whether CopyObject copies the tags of the source or
+// replaces them with the ones of the request.
+type TaggingDirective string
+
+const (
+	TaggingDirectiveCopy    TaggingDirective = "COPY"
+	TaggingDirectiveReplace TaggingDirective = "REPLACE"
+)
+
+// CopySource is the object read by CopyObject and UploadPartCopy. The copy
+// fails with ErrPreconditionFailed when one of the conditions is not met.
+type CopySource struct {
+	Bucket    string
+	Key       string
+	VersionID string
+
+	IfMatch           string
+	IfNoneMatch       string
+	IfModifiedSince   time.Time
+	IfUnmodifiedSince time.Time
+
+	// SSECustomerKey is the 256 bits key of a source encrypted with SSE-C.
+	SSECustomerKey []byte
+}
+
+// setHeaders sets the x-amz-copy-source-* headers.
+func (s *CopySource) setHeaders(header *fasthttp.RequestHeader) {
+	source := "/" + s.Bucket + "/" + functions.URIEncode(s.Key, true)
+	if s.VersionID != "" {
+		source += "?versionId=" + url.QueryEscape(s.VersionID)
+	}
+
+	header.Set(api.HeaderXAmzCopySource, source)
+
+	setHeader(header, api.HeaderXAmzCopySourceIfMatch, s.IfMatch)
+	setHeader(header, api.HeaderXAmzCopySourceIfNoneMatch, s.IfNoneMatch)
+	setDateHeader(header, api.HeaderXAmzCopySourceIfModifiedSince, s.IfModifiedSince)
+	setDateHeader(header, api.HeaderXAmzCopySourceIfUnmodifiedSince, s.IfUnmodifiedSince)
+
+	if s.SSECustomerKey != nil {
+		header.Set(api.HeaderXAmzCopySourceServerSideEncryptionCustomerAlgorithm, sseCustomerAlgorithm)
+		header.Set(api.HeaderXAmzCopySourceServerSideEncryptionCustomerKey, base64.StdEncoding.EncodeToString(s.SSECustomerKey))
+		header.Set(api.HeaderXAmzCopySourceServerSideEncryptionCustomerKeyMD5, signing.ContentMD5(s.SSECustomerKey))
+	}
+}
+
+type CopyObjectInput struct {
+	Bucket string
+	Key    string
+	Source CopySource
+
+	// MetadataDirective defaults to MetadataDirectiveCopy, ContentType and
+	// Metadata are only used with MetadataDirectiveReplace.
+	MetadataDirective MetadataDirective
+	ContentType       string
+	Metadata          map[string]string
+
+	// TaggingDirective defaults to TaggingDirectiveCopy, Tagging is only used
+	// with TaggingDirectiveReplace. It is validated before sending.
+	TaggingDirective TaggingDirective
+	Tagging          TagSet
+
+	StorageClass string
+
+	// ChecksumAlgorithm is the algorithm of the checksum of the copy,
+	// computed by S3.
+	ChecksumAlgorithm ChecksumAlgorithm
+}
+
+type CopyObjectOutput struct {
+	ETag            string
+	LastModified    time.Time
+	VersionID       string
+	SourceVersionID string
+
+	Checksums    Checksums
+	ChecksumType ChecksumType
+}
+
+// CopyObject copies an object of at most MaxPartSize bytes, see Copier for
+// larger objects. S3 may report a failure after having sent a 200 OK status,
+// it is returned as *api.Error.
+func (c *Client) CopyObject(ctx context.Context, input *CopyObjectInput) (*CopyObjectOutput, error) {
+	if input.TaggingDirective == TaggingDirectiveReplace {
+		if err := input.Tagging.Validate(); err != nil {
+			return nil, fmt.Errorf("CopyObject: %w", err)
+		}
+	}
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(resp)
+
+	if err := c.setRequestURI(req, input.Bucket, input.Key); err != nil {
+		return nil, err
+	}
+
+	req.Header.SetMethod(fasthttp.MethodPut)
+
+	input.Source.setHeaders(&req.Header)
+	setHeader(&req.Header, api.HeaderXAmzMetadataDirective, string(input.MetadataDirective))
+	setHeader(&req.Header, api.HeaderXAmzTaggingDirective, string(input.TaggingDirective))
+	setHeader(&req.Header, api.HeaderXAmzStorageClass, input.StorageClass)
+	setHeader(&req.Header, api.HeaderXAmzChecksumAlgorithm, string(input.ChecksumAlgorithm))
+
+	if input.MetadataDirective == MetadataDirectiveReplace {
+		setHeader(&req.Header, api.HeaderContentType, input.ContentType)
+		setMetadata(&req.Header, input.Metadata)
+	}
+
+	if input.TaggingDirective == TaggingDirectiveReplace {
+		setHeader(&req.Header, api.HeaderXAmzTagging, input.Tagging.encode())
+	}
+
+	if err := c.doBucket(ctx, input.Bucket, req, resp); err != nil {
+		return nil, conditionalError(err)
+	}
+
+	if err := checkErrorBody(resp); err != nil {
+		return nil, conditionalError(err)
+	}
+
+	var result api.CopyObjectResult
+	if err := xml.Unmarshal(resp.Body(), &result); err != nil {
+		return nil, fmt.Errorf("CopyObject: cannot parse response: %w", err)
+	}
+
+	return &CopyObjectOutput{
+		ETag:            result.ETag,
+		LastModified:    result.LastModified,
+		VersionID:       string(resp.Header.Peek(api.HeaderXAmzVersionID)),
+		SourceVersionID: string(resp.Header.Peek(api.HeaderXAmzCopySourceVersionID)),
+		Checksums:       checksumsFromAPI(result.Checksums),
+		ChecksumType:    ChecksumType(result.ChecksumType),
+	}, nil
+}
+
+// setDateHeader sets the header unless the date is zero.
+func setDateHeader(header *fasthttp.RequestHeader, key string, value time.Time) {
+	if !value.IsZero() {
+		header.SetBytesV(key, fasthttp.AppendHTTPDate(nil, value))
+	}
+}
+
+// setSSECustomerKey sets the headers of the objects encrypted with SSE-C.
+func setSSECustomerKey(header *fasthttp.RequestHeader, key []byte) {
+	if key != nil {
+		header.Set(api.HeaderXAmzServerSideEncryptionCustomerAlgorithm, sseCustomerAlgorithm)
+		header.Set(api.HeaderXAmzServerSideEncryptionCustomerKey, base64.StdEncoding.EncodeToString(key))
+		header.Set(api.HeaderXAmzServerSideEncryptionCustomerKeyMD5, signing.ContentMD5(key))
+	}
+}
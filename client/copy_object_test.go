@@ -0,0 +1,239 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lvjp/s3hobby/pkg/s3/api"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func TestCopyObject(t *testing.T) {
+	sseKey := []byte("0123456789abcdef0123456789abcdef")
+
+	testCases := []struct {
+		name  string
+		input *CopyObjectInput
+
+		expectedHeaders map[string]string
+		absentHeaders   []string
+	}{
+		{
+			name: "copy",
+			input: &CopyObjectInput{
+				Bucket: "examplebucket",
+				Key:    "copy of example",
+				Source: CopySource{Bucket: "sourcebucket", Key: "photos/2006/éte.jpg", VersionID: "3/L4kqtJl"},
+
+				// Ignored without the REPLACE directive.
+				ContentType: "image/png",
+				Metadata:    map[string]string{"author": "me"},
+			},
+			expectedHeaders: map[string]string{
+				"x-amz-copy-source": "/sourcebucket/photos/2006/%C3%A9te.jpg?versionId=3%2FL4kqtJl",
+			},
+			absentHeaders: []string{"content-type", "x-amz-meta-author", "x-amz-metadata-directive", "x-amz-copy-source-if-match"},
+		},
+		{
+			name: "replace",
+			input: &CopyObjectInput{
+				Bucket: "examplebucket",
+				Key:    "copy of example",
+				Source: CopySource{
+					Bucket:            "sourcebucket",
+					Key:               "example",
+					IfMatch:           `"etag"`,
+					IfNoneMatch:       `"other"`,
+					IfModifiedSince:   time.Date(2006, time.February, 3, 16, 45, 9, 0, time.UTC),
+					IfUnmodifiedSince: time.Date(2007, time.February, 3, 17, 45, 9, 0, time.FixedZone("CET", 3600)),
+					SSECustomerKey:    sseKey,
+				},
+				MetadataDirective: MetadataDirectiveReplace,
+				ContentType:       "image/png",
+				Metadata:          map[string]string{"author": "me"},
+				TaggingDirective:  TaggingDirectiveReplace,
+				StorageClass:      "STANDARD_IA",
+				ChecksumAlgorithm: ChecksumAlgorithmSHA256,
+			},
+			expectedHeaders: map[string]string{
+				"x-amz-copy-source":                                           "/sourcebucket/example",
+				"x-amz-copy-source-if-match":                                  `"etag"`,
+				"x-amz-copy-source-if-none-match":                             `"other"`,
+				"x-amz-copy-source-if-modified-since":                         "Fri, 03 Feb 2006 16:45:09 GMT",
+				"x-amz-copy-source-if-unmodified-since":                       "Sat, 03 Feb 2007 16:45:09 GMT",
+				"x-amz-copy-source-server-side-encryption-customer-algorithm": "AES256",
+				"x-amz-copy-source-server-side-encryption-customer-key":       "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=",
+				"x-amz-copy-source-server-side-encryption-customer-key-md5":   "hRasmdxgYDKV3nvbahU1MA==",
+				"x-amz-metadata-directive":                                    "REPLACE",
+				"x-amz-tagging-directive":                                     "REPLACE",
+				"x-amz-storage-class":                                         "STANDARD_IA",
+				"x-amz-checksum-algorithm":                                    "SHA256",
+				"content-type":                                                "image/png",
+				"x-amz-meta-author":                                           "me",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, httpClient, _ := newTestClient(t, func(*fasthttp.Request) string {
+				return rawResponse(fasthttp.StatusOK, `<?xml version="1.0" encoding="UTF-8"?>
+<CopyObjectResult>
+  <ETag>"6805f2cfc46c0f04559748bb039d69ae"</ETag>
+  <LastModified>2016-12-15T17:38:53.000Z</LastModified>
+  <ChecksumType>FULL_OBJECT</ChecksumType>
+  <ChecksumCRC64NVME>rosUhgp5mIg=</ChecksumCRC64NVME>
+</CopyObjectResult>`, "x-amz-version-id: NEWVERSION", "x-amz-copy-source-version-id: 3/L4kqtJl")
+			})
+
+			output, err := c.CopyObject(context.Background(), tc.input)
+			require.NoError(t, err)
+
+			assert.Equal(t, &CopyObjectOutput{
+				ETag:            `"6805f2cfc46c0f04559748bb039d69ae"`,
+				LastModified:    time.Date(2016, time.December, 15, 17, 38, 53, 0, time.UTC),
+				VersionID:       "NEWVERSION",
+				SourceVersionID: "3/L4kqtJl",
+				Checksums:       Checksums{CRC64NVME: "rosUhgp5mIg="},
+				ChecksumType:    ChecksumTypeFullObject,
+			}, output)
+
+			require.Len(t, httpClient.requests, 1)
+			sent := httpClient.requests[0]
+			assert.Equal(t, fasthttp.MethodPut, string(sent.Header.Method()))
+			assert.Equal(t, "https://examplebucket.s3.us-east-1.amazonaws.com/copy%20of%20example", sent.URI().String())
+
+			for key, value := range tc.expectedHeaders {
+				assert.Equal(t, value, string(sent.Header.Peek(key)), key)
+			}
+
+			for _, key := range tc.absentHeaders {
+				assert.Empty(t, sent.Header.Peek(key), key)
+			}
+		})
+	}
+
+	t.Run("error with 200 OK status", func(t *testing.T) {
+		c, _, _ := newTestClient(t, func(*fasthttp.Request) string {
+			return rawResponse(fasthttp.StatusOK, `<?xml version="1.0" encoding="UTF-8"?>
+
+<Error><Code>InternalError</Code><Message>We encountered an internal error.</Message></Error>`)
+		})
+
+		_, err := c.CopyObject(context.Background(), &CopyObjectInput{
+			Bucket: "examplebucket",
+			Key:    "copy",
+			Source: CopySource{Bucket: "sourcebucket", Key: "example"},
+		})
+
+		var apiErr *api.Error
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, "InternalError", apiErr.Code)
+	})
+}
+
+func TestUploadPartCopy(t *testing.T) {
+	c, httpClient, _ := newTestClient(t, func(*fasthttp.Request) string {
+		return rawResponse(fasthttp.StatusOK, `<?xml version="1.0" encoding="UTF-8"?>
+<CopyPartResult>
+  <LastModified>2009-10-28T22:32:00.000Z</LastModified>
+  <ETag>"9b2cf535f27731c974343645a3985328"</ETag>
+  <ChecksumCRC32>y/Q5Jg==</ChecksumCRC32>
+</CopyPartResult>`, "x-amz-copy-source-version-id: SOURCEVERSION")
+	})
+
+	output, err := c.UploadPartCopy(context.Background(), &UploadPartCopyInput{
+		Bucket:     "examplebucket",
+		Key:        "example-object",
+		UploadID:   "UPLOAD",
+		PartNumber: 3,
+		Source:     CopySource{Bucket: "sourcebucket", Key: "example", IfMatch: `"etag"`},
+		Range:      "bytes=0-1023",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, &UploadPartCopyOutput{
+		PartNumber:      3,
+		ETag:            `"9b2cf535f27731c974343645a3985328"`,
+		LastModified:    time.Date(2009, time.October, 28, 22, 32, 0, 0, time.UTC),
+		SourceVersionID: "SOURCEVERSION",
+		Checksums:       Checksums{CRC32: "y/Q5Jg=="},
+	}, output)
+
+	assert.Equal(t, CompletedPart{
+		PartNumber: 3,
+		ETag:       `"9b2cf535f27731c974343645a3985328"`,
+		Checksums:  Checksums{CRC32: "y/Q5Jg=="},
+	}, output.CompletedPart())
+
+	require.Len(t, httpClient.requests, 1)
+	sent := httpClient.requests[0]
+	assert.Equal(t, fasthttp.MethodPut, string(sent.Header.Method()))
+	assert.Equal(t, "partNumber=3&uploadId=UPLOAD", sent.URI().QueryArgs().String())
+	assert.Equal(t, "/sourcebucket/example", string(sent.Header.Peek(api.HeaderXAmzCopySource)))
+	assert.Equal(t, `"etag"`, string(sent.Header.Peek(api.HeaderXAmzCopySourceIfMatch)))
+	assert.Equal(t, "bytes=0-1023", string(sent.Header.Peek(api.HeaderXAmzCopySourceRange)))
+
+	_, err = c.UploadPartCopy(context.Background(), &UploadPartCopyInput{PartNumber: MaxPartNumber + 1})
+	require.EqualError(t, err, "UploadPartCopy: part number must be between 1 and 10000")
+}
+
+func TestCopyPreconditionFailed(t *testing.T) {
+	source := CopySource{Bucket: "sourcebucket", Key: "example", IfMatch: `"etag"`}
+
+	testCases := []struct {
+		name     string
+		response string
+		copy     func(c *Client) error
+	}{
+		{
+			name:     "CopyObject",
+			response: rawResponse(fasthttp.StatusPreconditionFailed, "<Error><Code>PreconditionFailed</Code></Error>"),
+			copy: func(c *Client) error {
+				_, err := c.CopyObject(context.Background(), &CopyObjectInput{Bucket: "examplebucket", Key: "copy", Source: source})
+				return err
+			},
+		},
+		{
+			name:     "CopyObject error body",
+			response: rawResponse(fasthttp.StatusOK, "<Error><Code>PreconditionFailed</Code></Error>"),
+			copy: func(c *Client) error {
+				_, err := c.CopyObject(context.Background(), &CopyObjectInput{Bucket: "examplebucket", Key: "copy", Source: source})
+				return err
+			},
+		},
+		{
+			name:     "UploadPartCopy",
+			response: rawResponse(fasthttp.StatusPreconditionFailed, "<Error><Code>PreconditionFailed</Code></Error>"),
+			copy: func(c *Client) error {
+				_, err := c.UploadPartCopy(context.Background(), &UploadPartCopyInput{
+					Bucket:     "examplebucket",
+					Key:        "copy",
+					UploadID:   "UPLOAD",
+					PartNumber: 1,
+					Source:     source,
+				})
+				return err
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, _, _ := newTestClient(t, func(*fasthttp.Request) string {
+				return tc.response
+			})
+
+			err := tc.copy(c)
+			require.ErrorIs(t, err, ErrPreconditionFailed)
+
+			var apiErr *api.Error
+			require.ErrorAs(t, err, &apiErr)
+			assert.Equal(t, api.ErrorCodePreconditionFailed, apiErr.Code)
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package client
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+
+	"github.com/lvjp/s3hobby/pkg/s3/api"
+
+	"github.com/valyala/fasthttp"
+)
+
+type BucketCannedACL string
+
+const (
+	BucketCannedACLPrivate           BucketCannedACL = "private"
+	BucketCannedACLPublicRead        BucketCannedACL = "public-read"
+	BucketCannedACLPublicReadWrite   BucketCannedACL = "public-read-write"
+	BucketCannedACLAuthenticatedRead BucketCannedACL = "authenticated-read"
+)
+
+type ObjectOwnership string
+
+const (
+	ObjectOwnershipBucketOwnerPreferred ObjectOwnership = "BucketOwnerPreferred"
+	ObjectOwnershipObjectWriter         ObjectOwnership = "ObjectWriter"
+	ObjectOwnershipBucketOwnerEnforced  ObjectOwnership = "BucketOwnerEnforced"
+)
+
+// defaultRegion is the location of buckets created without location constraint.
+const defaultRegion = "us-east-1"
+
+type CreateBucketInput struct {
+	Bucket string
+
+	// LocationConstraint defaults to the client region. It is never sent for
+	// us-east-1 which does not accept it.
+	LocationConstraint string
+
+	ACL                        BucketCannedACL
+	ObjectOwnership            ObjectOwnership
+	ObjectLockEnabledForBucket bool
+}
+
+type CreateBucketOutput struct {
+	Location string
+}
+
+// CreateBucket creates a general purpose bucket. Directory buckets are
+// managed by a dedicated regional endpoint which is not supported.
+func (c *Client) CreateBucket(ctx context.Context, input *CreateBucketInput) (*CreateBucketOutput, error) {
+	if isDirectoryBucket(input.Bucket) {
+		return nil, fmt.Errorf("CreateBucket: directory bucket %q is not supported", input.Bucket)
+	}
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(resp)
+
+	if err := c.setRequestURI(req, input.Bucket, ""); err != nil {
+		return nil, err
+	}
+
+	req.Header.SetMethod(fasthttp.MethodPut)
+
+	if input.ACL != "" {
+		req.Header.Set(api.HeaderXAmzACL, string(input.ACL))
+	}
+
+	if input.ObjectOwnership != "" {
+		req.Header.Set(api.HeaderXAmzObjectOwnership, string(input.ObjectOwnership))
+	}
+
+	if input.ObjectLockEnabledForBucket {
+		req.Header.Set(api.HeaderXAmzBucketObjectLockEnabled, "true")
+	}
+
+	locationConstraint := input.LocationConstraint
+	if locationConstraint == "" {
+		locationConstraint = c.options.Region
+	}
+
+	if locationConstraint != defaultRegion {
+		body, err := xml.Marshal(api.CreateBucketConfiguration{LocationConstraint: locationConstraint})
+		if err != nil {
+			return nil, fmt.Errorf("CreateBucket: cannot marshal configuration: %w", err)
+		}
+
+		req.SetBodyRaw(body)
+	}
+
+	if err := c.doBucket(ctx, input.Bucket, req, resp); err != nil {
+		return nil, err
+	}
+
+	return &CreateBucketOutput{
+		Location: string(resp.Header.Peek(api.HeaderLocation)),
+	}, nil
+}
@@ -0,0 +1,144 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lvjp/s3hobby/pkg/s3/api"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func TestCreateBucket(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input CreateBucketInput
+
+		expectedBody    string
+		expectedHeaders map[string]string
+	}{
+		{
+			name:  "client region",
+			input: CreateBucketInput{Bucket: "examplebucket"},
+		},
+		{
+			name:         "location constraint",
+			input:        CreateBucketInput{Bucket: "examplebucket", LocationConstraint: "eu-west-3"},
+			expectedBody: `<CreateBucketConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/"><LocationConstraint>eu-west-3</LocationConstraint></CreateBucketConfiguration>`,
+		},
+		{
+			name: "headers",
+			input: CreateBucketInput{
+				Bucket:                     "examplebucket",
+				ACL:                        BucketCannedACLPrivate,
+				ObjectOwnership:            ObjectOwnershipBucketOwnerEnforced,
+				ObjectLockEnabledForBucket: true,
+			},
+			expectedHeaders: map[string]string{
+				api.HeaderXAmzACL:                     "private",
+				api.HeaderXAmzObjectOwnership:         "BucketOwnerEnforced",
+				api.HeaderXAmzBucketObjectLockEnabled: "true",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, httpClient, _ := newTestClient(t, func(*fasthttp.Request) string {
+				return rawResponse(fasthttp.StatusOK, "", "Location: /examplebucket")
+			})
+
+			output, err := c.CreateBucket(context.Background(), &tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, "/examplebucket", output.Location)
+
+			require.Len(t, httpClient.requests, 1)
+			sent := httpClient.requests[0]
+			assert.Equal(t, fasthttp.MethodPut, string(sent.Header.Method()))
+			assert.Equal(t, "https://examplebucket.s3.us-east-1.amazonaws.com/", sent.URI().String())
+			assert.Equal(t, tc.expectedBody, string(sent.Body()))
+
+			for key, value := range tc.expectedHeaders {
+				assert.Equal(t, value, string(sent.Header.Peek(key)), key)
+			}
+		})
+	}
+
+	t.Run("other client region", func(t *testing.T) {
+		c, httpClient, _ := newTestClient(t, func(*fasthttp.Request) string {
+			return rawResponse(fasthttp.StatusOK, "")
+		})
+		c.options.Region = "eu-west-3"
+
+		_, err := c.CreateBucket(context.Background(), &CreateBucketInput{Bucket: "examplebucket"})
+		require.NoError(t, err)
+
+		require.Len(t, httpClient.requests, 1)
+		assert.Contains(t, string(httpClient.requests[0].Body()), "<LocationConstraint>eu-west-3</LocationConstraint>")
+	})
+
+	t.Run("directory bucket", func(t *testing.T) {
+		c, httpClient, _ := newTestClient(t, nil)
+
+		_, err := c.CreateBucket(context.Background(), &CreateBucketInput{Bucket: "mybucket--use1-az4--x-s3"})
+		require.Error(t, err)
+
+		_, err = c.DeleteBucket(context.Background(), &DeleteBucketInput{Bucket: "mybucket--use1-az4--x-s3"})
+		require.Error(t, err)
+
+		assert.Empty(t, httpClient.requests)
+	})
+}
+
+func TestDeleteBucket(t *testing.T) {
+	c, httpClient, _ := newTestClient(t, func(*fasthttp.Request) string {
+		return rawResponse(fasthttp.StatusNoContent, "")
+	})
+
+	_, err := c.DeleteBucket(context.Background(), &DeleteBucketInput{Bucket: "examplebucket"})
+	require.NoError(t, err)
+
+	require.Len(t, httpClient.requests, 1)
+	assert.Equal(t, fasthttp.MethodDelete, string(httpClient.requests[0].Header.Method()))
+	assert.Equal(t, "https://examplebucket.s3.us-east-1.amazonaws.com/", httpClient.requests[0].URI().String())
+}
+
+func TestHeadBucket(t *testing.T) {
+	t.Run("found", func(t *testing.T) {
+		c, _, _ := newTestClient(t, func(*fasthttp.Request) string {
+			return rawResponse(fasthttp.StatusOK, "", "X-Amz-Bucket-Region: eu-west-3", "X-Amz-Access-Point-Alias: false")
+		})
+
+		output, err := c.HeadBucket(context.Background(), &HeadBucketInput{Bucket: "examplebucket"})
+		require.NoError(t, err)
+		assert.Equal(t, &HeadBucketOutput{BucketRegion: "eu-west-3"}, output)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		c, _, _ := newTestClient(t, func(*fasthttp.Request) string {
+			return rawResponse(fasthttp.StatusNotFound, "")
+		})
+
+		_, err := c.HeadBucket(context.Background(), &HeadBucketInput{Bucket: "examplebucket"})
+
+		var apiErr *api.Error
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, fasthttp.StatusNotFound, apiErr.StatusCode)
+		assert.Empty(t, apiErr.BucketRegion)
+	})
+
+	t.Run("other region", func(t *testing.T) {
+		c, _, _ := newTestClient(t, func(*fasthttp.Request) string {
+			return rawResponse(fasthttp.StatusMovedPermanently, "", "X-Amz-Bucket-Region: eu-west-3")
+		})
+
+		_, err := c.HeadBucket(context.Background(), &HeadBucketInput{Bucket: "examplebucket"})
+
+		var apiErr *api.Error
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, fasthttp.StatusMovedPermanently, apiErr.StatusCode)
+		assert.Equal(t, "eu-west-3", apiErr.BucketRegion)
+	})
+}
@@ -0,0 +1,105 @@
+package client
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+
+	"github.com/lvjp/s3hobby/pkg/s3/api"
+
+	"github.com/valyala/fasthttp"
+)
+
+type CreateMultipartUploadInput struct {
+	Bucket string
+	Key    string
+
+	ContentType  string
+	Metadata     map[string]string
+	StorageClass string
+
+	CacheControl       string
+	ContentDisposition string
+	ContentEncoding    string
+	ContentLanguage    string
+
+	// Expires is sent verbatim, like returned by HeadObject.
+	Expires string
+
+	// Tagging is validated before sending.
+	Tagging TagSet
+
+	// ChecksumAlgorithm is the algorithm of the part checksums, which must
+	// then be provided by every UploadPart.
+	ChecksumAlgorithm ChecksumAlgorithm
+	ChecksumType      ChecksumType
+}
+
+type CreateMultipartUploadOutput struct {
+	Bucket   string
+	Key      string
+	UploadID string
+
+	ChecksumAlgorithm ChecksumAlgorithm
+	ChecksumType      ChecksumType
+}
+
+func (c *Client) CreateMultipartUpload(ctx context.Context, input *CreateMultipartUploadInput) (*CreateMultipartUploadOutput, error) {
+	if err := input.Tagging.Validate(); err != nil {
+		return nil, fmt.Errorf("CreateMultipartUpload: %w", err)
+	}
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(resp)
+
+	if err := c.setRequestURI(req, input.Bucket, input.Key); err != nil {
+		return nil, err
+	}
+
+	req.Header.SetMethod(fasthttp.MethodPost)
+	req.URI().QueryArgs().AddNoValue("uploads")
+
+	setHeader(&req.Header, api.HeaderContentType, input.ContentType)
+	setHeader(&req.Header, api.HeaderCacheControl, input.CacheControl)
+	setHeader(&req.Header, api.HeaderContentDisposition, input.ContentDisposition)
+	setHeader(&req.Header, api.HeaderContentEncoding, input.ContentEncoding)
+	setHeader(&req.Header, api.HeaderContentLanguage, input.ContentLanguage)
+	setHeader(&req.Header, api.HeaderExpires, input.Expires)
+	setHeader(&req.Header, api.HeaderXAmzStorageClass, input.StorageClass)
+	setHeader(&req.Header, api.HeaderXAmzTagging, input.Tagging.encode())
+	setHeader(&req.Header, api.HeaderXAmzChecksumAlgorithm, string(input.ChecksumAlgorithm))
+	setHeader(&req.Header, api.HeaderXAmzChecksumType, string(input.ChecksumType))
+	setMetadata(&req.Header, input.Metadata)
+
+	if err := c.doBucket(ctx, input.Bucket, req, resp); err != nil {
+		return nil, err
+	}
+
+	var result api.InitiateMultipartUploadResult
+	if err := xml.Unmarshal(resp.Body(), &result); err != nil {
+		return nil, fmt.Errorf("CreateMultipartUpload: cannot parse response: %w", err)
+	}
+
+	return &CreateMultipartUploadOutput{
+		Bucket:            result.Bucket,
+		Key:               result.Key,
+		UploadID:          result.UploadID,
+		ChecksumAlgorithm: ChecksumAlgorithm(resp.Header.Peek(api.HeaderXAmzChecksumAlgorithm)),
+		ChecksumType:      ChecksumType(resp.Header.Peek(api.HeaderXAmzChecksumType)),
+	}, nil
+}
+
+func setHeader(header *fasthttp.RequestHeader, key, value string) {
+	if value != "" {
+		header.Set(key, value)
+	}
+}
+
+// setMetadata sets the user defined metadata as x-amz-meta-* headers.
+func setMetadata(header *fasthttp.RequestHeader, metadata map[string]string) {
+	for key, value := range metadata {
+		header.Set(api.HeaderXAmzMetaPrefix+key, value)
+	}
+}
@@ -0,0 +1,132 @@
+package client
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lvjp/s3hobby/pkg/s3/api"
+	"github.com/lvjp/s3hobby/pkg/s3/signing"
+
+	"github.com/valyala/fasthttp"
+)
+
+type SessionMode string
+
+const (
+	SessionModeReadWrite SessionMode = "ReadWrite"
+	SessionModeReadOnly  SessionMode = "ReadOnly"
+)
+
+type CreateSessionInput struct {
+	// Bucket must be a directory bucket.
+	Bucket string
+
+	// SessionMode defaults to SessionModeReadWrite on the server side.
+	SessionMode SessionMode
+}
+
+type CreateSessionOutput struct {
+	Credentials signing.Credentials
+	Expiration  time.Time
+}
+
+// CreateSession requests temporary credentials for a directory bucket of S3
+// Express One Zone. Requests to directory buckets already manage their
+// sessions, calling it directly is rarely needed.
+func (c *Client) CreateSession(ctx context.Context, input *CreateSessionInput) (*CreateSessionOutput, error) {
+	if !isDirectoryBucket(input.Bucket) {
+		return nil, fmt.Errorf("CreateSession: %q is not a directory bucket", input.Bucket)
+	}
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(resp)
+
+	if err := c.setRequestURI(req, input.Bucket, ""); err != nil {
+		return nil, err
+	}
+
+	req.Header.SetMethod(fasthttp.MethodGet)
+	req.URI().QueryArgs().AddNoValue("session")
+
+	if input.SessionMode != "" {
+		req.Header.Set(api.HeaderXAmzCreateSessionMode, string(input.SessionMode))
+	}
+
+	// The session itself is requested with the client credentials.
+	if err := c.do(ctx, req, resp, c.options.Credentials, signing.ServiceS3Express); err != nil {
+		return nil, err
+	}
+
+	var result api.CreateSessionResult
+	if err := xml.Unmarshal(resp.Body(), &result); err != nil {
+		return nil, fmt.Errorf("CreateSession: cannot parse response: %w", err)
+	}
+
+	return &CreateSessionOutput{
+		Credentials: signing.Credentials{
+			AccessKeyID:     result.Credentials.AccessKeyID,
+			SecretAccessKey: result.Credentials.SecretAccessKey,
+			SessionToken:    result.Credentials.SessionToken,
+		},
+		Expiration: result.Credentials.Expiration,
+	}, nil
+}
+
+// sessionRefreshMargin is how long before their expiration sessions are renewed.
+const sessionRefreshMargin = time.Minute
+
+// sessionCache holds the session credentials of each directory bucket.
+type sessionCache struct {
+	mu      sync.Mutex
+	entries map[string]*sessionEntry
+}
+
+type sessionEntry struct {
+	mu      sync.Mutex
+	session *CreateSessionOutput
+}
+
+func (sc *sessionCache) entry(bucket string) *sessionEntry {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	if sc.entries == nil {
+		sc.entries = make(map[string]*sessionEntry)
+	}
+
+	entry, exists := sc.entries[bucket]
+	if !exists {
+		entry = &sessionEntry{}
+		sc.entries[bucket] = entry
+	}
+
+	return entry
+}
+
+// sessionCredentials returns valid session credentials for the directory
+// bucket, creating a new session when needed.
+func (c *Client) sessionCredentials(ctx context.Context, bucket string) (signing.Credentials, error) {
+	entry := c.sessions.entry(bucket)
+
+	// Only one session is created at a time for a given bucket.
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+
+	if entry.session != nil && entry.session.Expiration.Sub(c.options.Clock.Now()) > sessionRefreshMargin {
+		return entry.session.Credentials, nil
+	}
+
+	session, err := c.CreateSession(ctx, &CreateSessionInput{Bucket: bucket})
+	if err != nil {
+		return signing.Credentials{}, err
+	}
+
+	entry.session = session
+
+	return session.Credentials, nil
+}
@@ -0,0 +1,39 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/valyala/fasthttp"
+)
+
+type DeleteBucketInput struct {
+	Bucket string
+}
+
+type DeleteBucketOutput struct{}
+
+// DeleteBucket deletes an empty general purpose bucket. Directory buckets are
+// managed by a dedicated regional endpoint which is not supported.
+func (c *Client) DeleteBucket(ctx context.Context, input *DeleteBucketInput) (*DeleteBucketOutput, error) {
+	if isDirectoryBucket(input.Bucket) {
+		return nil, fmt.Errorf("DeleteBucket: directory bucket %q is not supported", input.Bucket)
+	}
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(resp)
+
+	if err := c.setRequestURI(req, input.Bucket, ""); err != nil {
+		return nil, err
+	}
+
+	req.Header.SetMethod(fasthttp.MethodDelete)
+
+	if err := c.doBucket(ctx, input.Bucket, req, resp); err != nil {
+		return nil, err
+	}
+
+	return &DeleteBucketOutput{}, nil
+}
@@ -0,0 +1,38 @@
+package client
+
+import (
+	"context"
+
+	"github.com/lvjp/s3hobby/pkg/s3/api"
+
+	"github.com/valyala/fasthttp"
+)
+
+type DeleteObjectTaggingInput struct {
+	Bucket    string
+	Key       string
+	VersionID string
+}
+
+type DeleteObjectTaggingOutput struct {
+	VersionID string
+}
+
+func (c *Client) DeleteObjectTagging(ctx context.Context, input *DeleteObjectTaggingInput) (*DeleteObjectTaggingOutput, error) {
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(resp)
+
+	if err := c.setTaggingRequest(req, fasthttp.MethodDelete, input.Bucket, input.Key, input.VersionID); err != nil {
+		return nil, err
+	}
+
+	if err := c.doBucket(ctx, input.Bucket, req, resp); err != nil {
+		return nil, err
+	}
+
+	return &DeleteObjectTaggingOutput{
+		VersionID: string(resp.Header.Peek(api.HeaderXAmzVersionID)),
+	}, nil
+}
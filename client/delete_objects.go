@@ -0,0 +1,155 @@
+package client
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+
+	"github.com/lvjp/s3hobby/pkg/s3/api"
+	"github.com/lvjp/s3hobby/pkg/s3/signing"
+
+	"github.com/valyala/fasthttp"
+)
+
+// MaxDeleteObjects is the largest number of objects deleted by DeleteObjects.
+const MaxDeleteObjects = 1000
+
+type ObjectIdentifier struct {
+	Key string
+
+	// VersionID deletes a specific version, the latest one gets a delete
+	// marker in versioned buckets otherwise.
+	VersionID string
+}
+
+type DeleteObjectsInput struct {
+	Bucket  string
+	Objects []ObjectIdentifier
+
+	// Quiet only reports the objects whose deletion failed.
+	Quiet bool
+
+	// ChecksumAlgorithm adds a checksum of the request body along its
+	// Content-MD5, which is always sent.
+	ChecksumAlgorithm ChecksumAlgorithm
+}
+
+type DeletedObject struct {
+	Key       string
+	VersionID string
+
+	// DeleteMarker tells whether a delete marker was either created or
+	// deleted, DeleteMarkerVersionID being its version.
+	DeleteMarker          bool
+	DeleteMarkerVersionID string
+}
+
+// DeleteObjectError reports the failed deletion of a single object.
+type DeleteObjectError struct {
+	Key       string
+	VersionID string
+	Code      string
+	Message   string
+}
+
+func (e *DeleteObjectError) Error() string {
+	key := e.Key
+	if e.VersionID != "" {
+		key += " (version " + e.VersionID + ")"
+	}
+
+	if e.Message == "" {
+		return fmt.Sprintf("s3: cannot delete %s: %s", key, e.Code)
+	}
+
+	return fmt.Sprintf("s3: cannot delete %s: %s: %s", key, e.Code, e.Message)
+}
+
+type DeleteObjectsOutput struct {
+	// Deleted is empty in quiet mode.
+	Deleted []DeletedObject
+	Errors  []DeleteObjectError
+}
+
+// DeleteObjects deletes up to MaxDeleteObjects objects in a single request,
+// see Deleter for more objects. The request succeeds even when some of the
+// deletions fail, they are reported by Errors.
+func (c *Client) DeleteObjects(ctx context.Context, input *DeleteObjectsInput) (*DeleteObjectsOutput, error) {
+	if len(input.Objects) == 0 || len(input.Objects) > MaxDeleteObjects {
+		return nil, errors.New("DeleteObjects: between 1 and 1000 objects are required")
+	}
+
+	document := api.Delete{
+		Objects: make([]api.ObjectIdentifier, 0, len(input.Objects)),
+		Quiet:   input.Quiet,
+	}
+
+	for _, object := range input.Objects {
+		document.Objects = append(document.Objects, api.ObjectIdentifier{
+			Key:       object.Key,
+			VersionID: object.VersionID,
+		})
+	}
+
+	body, err := xml.Marshal(document)
+	if err != nil {
+		return nil, fmt.Errorf("DeleteObjects: cannot marshal objects: %w", err)
+	}
+
+	var checksums Checksums
+	if err := checksums.compute(input.ChecksumAlgorithm, body); err != nil {
+		return nil, err
+	}
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(resp)
+
+	if err := c.setRequestURI(req, input.Bucket, ""); err != nil {
+		return nil, err
+	}
+
+	req.Header.SetMethod(fasthttp.MethodPost)
+	req.URI().QueryArgs().AddNoValue("delete")
+
+	checksums.setHeaders(&req.Header)
+	req.SetBodyRaw(body)
+
+	// S3 requires an integrity check of the body, whatever the client options.
+	if err := signing.SetContentMD5(signing.NewFastHTTPRequest(req)); err != nil {
+		return nil, err
+	}
+
+	if err := c.doBucket(ctx, input.Bucket, req, resp); err != nil {
+		return nil, err
+	}
+
+	var result api.DeleteResult
+	if err := xml.Unmarshal(resp.Body(), &result); err != nil {
+		return nil, fmt.Errorf("DeleteObjects: cannot parse response: %w", err)
+	}
+
+	output := &DeleteObjectsOutput{}
+
+	for _, deleted := range result.Deleted {
+		output.Deleted = append(output.Deleted, DeletedObject{
+			Key:                   deleted.Key,
+			VersionID:             deleted.VersionID,
+			DeleteMarker:          deleted.DeleteMarker,
+			DeleteMarkerVersionID: deleted.DeleteMarkerVersionID,
+		})
+	}
+
+	for _, deleteErr := range result.Errors {
+		output.Errors = append(output.Errors, DeleteObjectError{
+			Key:       deleteErr.Key,
+			VersionID: deleteErr.VersionID,
+			Code:      deleteErr.Code,
+			Message:   deleteErr.Message,
+		})
+	}
+
+	return output, nil
+}
@@ -0,0 +1,114 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lvjp/s3hobby/pkg/s3/api"
+	"github.com/lvjp/s3hobby/pkg/s3/signing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func TestDeleteObjects(t *testing.T) {
+	c, httpClient, _ := newTestClient(t, func(*fasthttp.Request) string {
+		return rawResponse(fasthttp.StatusOK, `<?xml version="1.0" encoding="UTF-8"?>
+<DeleteResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+  <Deleted>
+    <Key>sample1.txt</Key>
+  </Deleted>
+  <Deleted>
+    <Key>sample2.txt</Key>
+    <VersionId>OYcLXagmS.WaD..oyH4KRguB95_YhLs7</VersionId>
+    <DeleteMarker>true</DeleteMarker>
+    <DeleteMarkerVersionId>NeQt5xeFTfgPJD8B4CGWnkSLtluMr11s</DeleteMarkerVersionId>
+  </Deleted>
+  <Error>
+    <Key>sample3.txt</Key>
+    <Code>AccessDenied</Code>
+    <Message>Access Denied</Message>
+  </Error>
+</DeleteResult>`)
+	})
+
+	output, err := c.DeleteObjects(context.Background(), &DeleteObjectsInput{
+		Bucket: "examplebucket",
+		Objects: []ObjectIdentifier{
+			{Key: "sample1.txt"},
+			{Key: "sample2.txt", VersionID: "OYcLXagmS.WaD..oyH4KRguB95_YhLs7"},
+			{Key: "sample3.txt"},
+		},
+		ChecksumAlgorithm: ChecksumAlgorithmCRC32,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, &DeleteObjectsOutput{
+		Deleted: []DeletedObject{
+			{Key: "sample1.txt"},
+			{
+				Key:                   "sample2.txt",
+				VersionID:             "OYcLXagmS.WaD..oyH4KRguB95_YhLs7",
+				DeleteMarker:          true,
+				DeleteMarkerVersionID: "NeQt5xeFTfgPJD8B4CGWnkSLtluMr11s",
+			},
+		},
+		Errors: []DeleteObjectError{
+			{Key: "sample3.txt", Code: "AccessDenied", Message: "Access Denied"},
+		},
+	}, output)
+
+	require.EqualError(t, &output.Errors[0], "s3: cannot delete sample3.txt: AccessDenied: Access Denied")
+
+	require.Len(t, httpClient.requests, 1)
+	sent := httpClient.requests[0]
+	assert.Equal(t, fasthttp.MethodPost, string(sent.Header.Method()))
+	assert.Equal(t, "https://examplebucket.s3.us-east-1.amazonaws.com/?delete", sent.URI().String())
+
+	body := `<Delete xmlns="http://s3.amazonaws.com/doc/2006-03-01/">` +
+		`<Object><Key>sample1.txt</Key></Object>` +
+		`<Object><Key>sample2.txt</Key><VersionId>OYcLXagmS.WaD..oyH4KRguB95_YhLs7</VersionId></Object>` +
+		`<Object><Key>sample3.txt</Key></Object>` +
+		`</Delete>`
+	assert.Equal(t, body, string(sent.Body()))
+
+	checksum, err := computeChecksum(ChecksumAlgorithmCRC32, []byte(body))
+	require.NoError(t, err)
+	assert.Equal(t, checksum, string(sent.Header.Peek(api.HeaderXAmzChecksumCrc32)))
+	assert.Equal(t, signing.ContentMD5([]byte(body)), string(sent.Header.Peek(api.HeaderContentMD5)))
+}
+
+func TestDeleteObjectsQuiet(t *testing.T) {
+	c, httpClient, _ := newTestClient(t, func(*fasthttp.Request) string {
+		return rawResponse(fasthttp.StatusOK, `<DeleteResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/"></DeleteResult>`)
+	})
+
+	output, err := c.DeleteObjects(context.Background(), &DeleteObjectsInput{
+		Bucket:  "examplebucket",
+		Objects: []ObjectIdentifier{{Key: "sample1.txt"}},
+		Quiet:   true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &DeleteObjectsOutput{}, output)
+
+	require.Len(t, httpClient.requests, 1)
+	assert.Equal(t,
+		`<Delete xmlns="http://s3.amazonaws.com/doc/2006-03-01/"><Object><Key>sample1.txt</Key></Object><Quiet>true</Quiet></Delete>`,
+		string(httpClient.requests[0].Body()))
+}
+
+func TestDeleteObjectsInvalid(t *testing.T) {
+	c, httpClient, _ := newTestClient(t, nil)
+
+	_, err := c.DeleteObjects(context.Background(), &DeleteObjectsInput{Bucket: "examplebucket"})
+	require.EqualError(t, err, "DeleteObjects: between 1 and 1000 objects are required")
+
+	_, err = c.DeleteObjects(context.Background(), &DeleteObjectsInput{
+		Bucket:  "examplebucket",
+		Objects: make([]ObjectIdentifier, MaxDeleteObjects+1),
+	})
+	require.EqualError(t, err, "DeleteObjects: between 1 and 1000 objects are required")
+
+	assert.Empty(t, httpClient.requests)
+}
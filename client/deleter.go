@@ -0,0 +1,188 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"sync"
+)
+
+const DefaultDeleteConcurrency = 5
+
+type DeleterOptions struct {
+	// Concurrency is the number of DeleteObjects requests sent in parallel, it
+	// defaults to DefaultDeleteConcurrency.
+	Concurrency int
+}
+
+// Deleter deletes any number of objects with concurrent DeleteObjects
+// requests. It is safe for concurrent use.
+type Deleter struct {
+	client  *Client
+	options DeleterOptions
+}
+
+func NewDeleter(client *Client, options DeleterOptions) (*Deleter, error) {
+	if options.Concurrency == 0 {
+		options.Concurrency = DefaultDeleteConcurrency
+	}
+
+	if options.Concurrency < 1 {
+		return nil, errors.New("Deleter: concurrency must be positive")
+	}
+
+	return &Deleter{
+		client:  client,
+		options: options,
+	}, nil
+}
+
+type DeleteAllInput struct {
+	Bucket string
+
+	// Objects are deleted by batches of MaxDeleteObjects, see ObjectKeys to
+	// delete the objects of a listing.
+	Objects iter.Seq2[ObjectIdentifier, error]
+
+	ChecksumAlgorithm ChecksumAlgorithm
+}
+
+type DeleteAllOutput struct {
+	// Deleted is the number of deleted objects.
+	Deleted int
+
+	// Errors reports the objects whose deletion failed.
+	Errors []DeleteObjectError
+}
+
+// Err returns the deletion failures joined in a single error, nil when every
+// object was deleted.
+func (o *DeleteAllOutput) Err() error {
+	errs := make([]error, 0, len(o.Errors))
+	for index := range o.Errors {
+		errs = append(errs, &o.Errors[index])
+	}
+
+	return errors.Join(errs...)
+}
+
+// DeleteAll deletes every object of the iterator. The batches are sent in
+// quiet mode while the iteration goes on, so that deleting the objects of a
+// listing does not need to hold the whole listing in memory:
+//
+//	deleter.DeleteAll(ctx, &DeleteAllInput{
+//		Bucket:  bucket,
+//		Objects: ObjectKeys(c.ObjectsV2(ctx, &ListObjectsV2Input{Bucket: bucket, Prefix: prefix})),
+//	})
+//
+// The failed deletions of single objects are reported by the output, an error
+// is only returned when the iteration or a request fails. Some objects may
+// have been deleted anyway.
+func (d *Deleter) DeleteAll(ctx context.Context, input *DeleteAllInput) (*DeleteAllOutput, error) {
+	deletion := &objectsDeletion{
+		deleter: d,
+		input:   input,
+		output:  &DeleteAllOutput{},
+	}
+
+	if err := deletion.run(ctx); err != nil {
+		return nil, err
+	}
+
+	return deletion.output, nil
+}
+
+// ObjectKeys returns the identifiers of the latest version of the listed
+// objects.
+func ObjectKeys(objects iter.Seq2[Object, error]) iter.Seq2[ObjectIdentifier, error] {
+	return func(yield func(ObjectIdentifier, error) bool) {
+		for object, err := range objects {
+			if !yield(ObjectIdentifier{Key: object.Key}, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// objectsDeletion holds the state of a DeleteAll.
+type objectsDeletion struct {
+	deleter *Deleter
+	input   *DeleteAllInput
+
+	mu     sync.Mutex
+	output *DeleteAllOutput
+}
+
+func (o *objectsDeletion) run(ctx context.Context) error {
+	group := newTaskGroup(ctx, o.deleter.options.Concurrency)
+
+	// The batches already sent are still deleted when the iteration fails.
+	iterationErr := o.sendBatches(group)
+
+	if err := group.Wait(); err != nil {
+		return err
+	}
+
+	return iterationErr
+}
+
+// sendBatches reads the iterator and sends the batches until its end or a
+// failure, it returns the error of the iterator.
+func (o *objectsDeletion) sendBatches(group *taskGroup) error {
+	send := func(batch []ObjectIdentifier) bool {
+		if !group.Acquire() {
+			return false
+		}
+
+		group.Go(func(ctx context.Context) error {
+			return o.deleteBatch(ctx, batch)
+		})
+
+		return true
+	}
+
+	batch := make([]ObjectIdentifier, 0, MaxDeleteObjects)
+
+	for object, err := range o.input.Objects {
+		if err != nil {
+			return err
+		}
+
+		batch = append(batch, object)
+		if len(batch) < MaxDeleteObjects {
+			continue
+		}
+
+		if !send(batch) {
+			return nil
+		}
+
+		batch = make([]ObjectIdentifier, 0, MaxDeleteObjects)
+	}
+
+	if len(batch) > 0 {
+		send(batch)
+	}
+
+	return nil
+}
+
+func (o *objectsDeletion) deleteBatch(ctx context.Context, batch []ObjectIdentifier) error {
+	output, err := o.deleter.client.DeleteObjects(ctx, &DeleteObjectsInput{
+		Bucket:            o.input.Bucket,
+		Objects:           batch,
+		Quiet:             true,
+		ChecksumAlgorithm: o.input.ChecksumAlgorithm,
+	})
+	if err != nil {
+		return err
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.output.Deleted += len(batch) - len(output.Errors)
+	o.output.Errors = append(o.output.Errors, output.Errors...)
+
+	return nil
+}
@@ -0,0 +1,220 @@
+package client
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"iter"
+	"testing"
+
+	"github.com/lvjp/s3hobby/pkg/s3/api"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+// newDeleteServer returns a server deleting keys, failing the ones listed in
+// denied. failBatch, unless zero, answers the batch of this index with an
+// internal error.
+func newDeleteServer(t *testing.T, denied map[string]bool, failBatch int) *fakeServer {
+	t.Helper()
+
+	var batch int
+
+	server := newFakeServer(t)
+	server.on(fasthttp.MethodPost, "delete", func(req *fasthttp.Request) string {
+		var document api.Delete
+		if err := xml.Unmarshal(req.Body(), &document); err != nil {
+			return rawResponse(fasthttp.StatusBadRequest, "<Error><Code>MalformedXML</Code></Error>")
+		}
+
+		if batch++; batch == failBatch {
+			return rawResponse(fasthttp.StatusInternalServerError, "<Error><Code>InternalError</Code></Error>")
+		}
+
+		var result api.DeleteResult
+		for _, object := range document.Objects {
+			if denied[object.Key] {
+				result.Errors = append(result.Errors, api.DeleteError{Key: object.Key, Code: "AccessDenied"})
+			}
+		}
+
+		body, _ := xml.Marshal(result)
+
+		return rawResponse(fasthttp.StatusOK, string(body))
+	})
+
+	return server
+}
+
+// batches returns the keys of the DeleteObjects requests sent to the server.
+func batches(t *testing.T, server *fakeServer) [][]string {
+	t.Helper()
+
+	var ret [][]string
+
+	for _, req := range server.requests(fasthttp.MethodPost, "delete") {
+		var document api.Delete
+		require.NoError(t, xml.Unmarshal(req.Body(), &document))
+
+		keys := make([]string, 0, len(document.Objects))
+		for _, object := range document.Objects {
+			keys = append(keys, object.Key)
+		}
+
+		ret = append(ret, keys)
+	}
+
+	return ret
+}
+
+// batchSizes returns the number of keys of each batch sent to the server.
+func batchSizes(t *testing.T, server *fakeServer) []int {
+	t.Helper()
+
+	var ret []int
+	for _, keys := range batches(t, server) {
+		ret = append(ret, len(keys))
+	}
+
+	return ret
+}
+
+func testKeys(count int) iter.Seq2[ObjectIdentifier, error] {
+	return func(yield func(ObjectIdentifier, error) bool) {
+		for index := range count {
+			if !yield(ObjectIdentifier{Key: fmt.Sprintf("prefix/%04d", index)}, nil) {
+				return
+			}
+		}
+	}
+}
+
+var testDeleterOptions = DeleterOptions{Concurrency: 2}
+
+func TestDeleter(t *testing.T) {
+	t.Run("batches", func(t *testing.T) {
+		server := newDeleteServer(t, map[string]bool{"prefix/0042": true, "prefix/2001": true}, 0)
+		deleter := newTestHelper(t, server, NewDeleter, testDeleterOptions)
+
+		output, err := deleter.DeleteAll(context.Background(), &DeleteAllInput{
+			Bucket:  "examplebucket",
+			Objects: testKeys(2500),
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, 2498, output.Deleted)
+		assert.ElementsMatch(t, []DeleteObjectError{
+			{Key: "prefix/0042", Code: "AccessDenied"},
+			{Key: "prefix/2001", Code: "AccessDenied"},
+		}, output.Errors)
+		var expected, sent []string
+		for object := range testKeys(2500) {
+			expected = append(expected, object.Key)
+		}
+
+		for _, keys := range batches(t, server) {
+			sent = append(sent, keys...)
+		}
+
+		assert.ElementsMatch(t, []int{1000, 1000, 500}, batchSizes(t, server))
+		assert.ElementsMatch(t, expected, sent)
+
+		var deleteErr *DeleteObjectError
+		require.ErrorAs(t, output.Err(), &deleteErr)
+		assert.Equal(t, "AccessDenied", deleteErr.Code)
+	})
+
+	t.Run("no failure", func(t *testing.T) {
+		server := newDeleteServer(t, nil, 0)
+		deleter := newTestHelper(t, server, NewDeleter, testDeleterOptions)
+
+		output, err := deleter.DeleteAll(context.Background(), &DeleteAllInput{
+			Bucket:  "examplebucket",
+			Objects: testKeys(3),
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, &DeleteAllOutput{Deleted: 3}, output)
+		require.NoError(t, output.Err())
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		server := newDeleteServer(t, nil, 0)
+		deleter := newTestHelper(t, server, NewDeleter, testDeleterOptions)
+
+		output, err := deleter.DeleteAll(context.Background(), &DeleteAllInput{
+			Bucket:  "examplebucket",
+			Objects: testKeys(0),
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, &DeleteAllOutput{}, output)
+		assert.Empty(t, batchSizes(t, server))
+	})
+
+	t.Run("failed request", func(t *testing.T) {
+		server := newDeleteServer(t, nil, 1)
+		deleter := newTestHelper(t, server, NewDeleter, testDeleterOptions)
+
+		_, err := deleter.DeleteAll(context.Background(), &DeleteAllInput{
+			Bucket:  "examplebucket",
+			Objects: testKeys(5000),
+		})
+		require.EqualError(t, err, "s3: 500 InternalError")
+		assert.Less(t, len(batchSizes(t, server)), 5, "the deletion must stop after a failure")
+	})
+
+	t.Run("failed iteration", func(t *testing.T) {
+		server := newDeleteServer(t, nil, 0)
+		deleter := newTestHelper(t, server, NewDeleter, testDeleterOptions)
+
+		listErr := errors.New("listing failed")
+
+		_, err := deleter.DeleteAll(context.Background(), &DeleteAllInput{
+			Bucket: "examplebucket",
+			Objects: func(yield func(ObjectIdentifier, error) bool) {
+				for object := range testKeys(1500) {
+					if !yield(object, nil) {
+						return
+					}
+				}
+
+				yield(ObjectIdentifier{}, listErr)
+			},
+		})
+		require.ErrorIs(t, err, listErr)
+		assert.Equal(t, []int{1000}, batchSizes(t, server), "the incomplete batch must not be sent")
+	})
+}
+
+func TestObjectKeys(t *testing.T) {
+	c, _, _ := newTestClient(t, func(*fasthttp.Request) string {
+		return rawResponse(fasthttp.StatusOK, `<ListBucketResult>
+  <Contents><Key>photos/a.jpg</Key></Contents>
+  <Contents><Key>photos/b.jpg</Key></Contents>
+</ListBucketResult>`)
+	})
+
+	var keys []ObjectIdentifier
+
+	for object, err := range ObjectKeys(c.ObjectsV2(context.Background(), &ListObjectsV2Input{Bucket: "examplebucket", Prefix: "photos/"})) {
+		require.NoError(t, err)
+		keys = append(keys, object)
+	}
+
+	assert.Equal(t, []ObjectIdentifier{{Key: "photos/a.jpg"}, {Key: "photos/b.jpg"}}, keys)
+}
+
+func TestNewDeleter(t *testing.T) {
+	c, _, _ := newTestClient(t, nil)
+
+	deleter, err := NewDeleter(c, DeleterOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultDeleteConcurrency, deleter.options.Concurrency)
+
+	_, err = NewDeleter(c, DeleterOptions{Concurrency: -1})
+	require.EqualError(t, err, "Deleter: concurrency must be positive")
+}
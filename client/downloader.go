@@ -0,0 +1,299 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/lvjp/s3hobby/pkg/s3/api"
+
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	DefaultDownloadPartSize    int64 = 8 << 20
+	DefaultDownloadConcurrency       = 5
+	DefaultDownloadMaxAttempts       = 3
+)
+
+type DownloaderOptions struct {
+	// PartSize is the size of the ranges, it defaults to DefaultDownloadPartSize.
+	PartSize int64
+
+	// Concurrency is the number of ranges downloaded in parallel, it defaults
+	// to DefaultDownloadConcurrency.
+	Concurrency int
+
+	// MaxAttempts is the number of attempts of every range, it defaults to
+	// DefaultDownloadMaxAttempts. Only network and server errors are retried.
+	MaxAttempts int
+}
+
+// Downloader downloads objects with concurrent range requests. It is safe for
+// concurrent use.
+type Downloader struct {
+	client  *Client
+	options DownloaderOptions
+}
+
+func NewDownloader(client *Client, options DownloaderOptions) (*Downloader, error) {
+	if options.PartSize == 0 {
+		options.PartSize = DefaultDownloadPartSize
+	}
+
+	if options.Concurrency == 0 {
+		options.Concurrency = DefaultDownloadConcurrency
+	}
+
+	if options.MaxAttempts == 0 {
+		options.MaxAttempts = DefaultDownloadMaxAttempts
+	}
+
+	if options.PartSize < 1 || options.Concurrency < 1 || options.MaxAttempts < 1 {
+		return nil, errors.New("Downloader: part size, concurrency and max attempts must be positive")
+	}
+
+	return &Downloader{
+		client:  client,
+		options: options,
+	}, nil
+}
+
+type DownloadInput struct {
+	Bucket    string
+	Key       string
+	VersionID string
+}
+
+type DownloadOutput struct {
+	// ObjectHeaders are the ones returned by HeadObject before the download.
+	ObjectHeaders
+
+	// ValidatedChecksum is the algorithm of the object checksum checked
+	// against the downloaded content. It is empty when the object has no
+	// checksum or a composite one, or when its algorithm cannot be combined
+	// over several ranges, like SHA256.
+	ValidatedChecksum ChecksumAlgorithm
+}
+
+// Download writes the object content to w. Every range is pinned to the ETag
+// returned by HeadObject, so that the download fails when the object is
+// overwritten meanwhile. The content written to w is incomplete on error.
+func (d *Downloader) Download(ctx context.Context, w io.WriterAt, input *DownloadInput) (*DownloadOutput, error) {
+	head, err := d.client.HeadObject(ctx, &HeadObjectInput{
+		Bucket:       input.Bucket,
+		Key:          input.Key,
+		VersionID:    input.VersionID,
+		ChecksumMode: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case head.ContentLength < 0:
+		return nil, fmt.Errorf("Downloader: %s: unknown object size", input.Key)
+	case head.ContentLength == 0:
+		// Nothing to download, and S3 rejects ranges over an empty object.
+		return &DownloadOutput{ObjectHeaders: head.ObjectHeaders}, nil
+	}
+
+	download := &objectDownload{
+		downloader: d,
+		writer:     w,
+		input:      input,
+		etag:       head.ETag,
+		size:       head.ContentLength,
+		count:      int((head.ContentLength + d.options.PartSize - 1) / d.options.PartSize),
+	}
+
+	download.checksums = newRangeChecksums(head.Checksums, download.count)
+
+	if err := download.run(ctx); err != nil {
+		return nil, err
+	}
+
+	output := &DownloadOutput{ObjectHeaders: head.ObjectHeaders}
+
+	if download.checksums != nil {
+		if err := download.checksums.check(); err != nil {
+			return nil, fmt.Errorf("Downloader: %s: %w", input.Key, err)
+		}
+
+		output.ValidatedChecksum = download.checksums.algorithm
+	}
+
+	return output, nil
+}
+
+// objectDownload holds the state of a Download.
+type objectDownload struct {
+	downloader *Downloader
+	writer     io.WriterAt
+	input      *DownloadInput
+	etag       string
+
+	size  int64
+	count int
+
+	// checksums is nil when the object checksum cannot be validated.
+	checksums *rangeChecksums
+}
+
+func (o *objectDownload) run(ctx context.Context) error {
+	group := newTaskGroup(ctx, o.downloader.options.Concurrency)
+
+	for index := range o.count {
+		if !group.Acquire() {
+			break
+		}
+
+		group.Go(func(ctx context.Context) error {
+			return o.downloadRange(ctx, index)
+		})
+	}
+
+	return group.Wait()
+}
+
+// downloadRange downloads and writes the range, retrying network and server
+// errors.
+func (o *objectDownload) downloadRange(ctx context.Context, index int) error {
+	start := int64(index) * o.downloader.options.PartSize
+	end := min(start+o.downloader.options.PartSize, o.size)
+
+	input := &GetObjectInput{
+		Bucket:    o.input.Bucket,
+		Key:       o.input.Key,
+		VersionID: o.input.VersionID,
+		Range:     NewByteRange(start, end-1),
+		IfMatch:   o.etag,
+	}
+
+	for attempt := 1; ; attempt++ {
+		var writeErr error
+
+		err := o.downloader.client.getObject(ctx, input, func(resp *fasthttp.Response, _ bool) error {
+			body := resp.Body()
+			if int64(len(body)) != end-start {
+				return fmt.Errorf("range %d-%d: received %d bytes", start, end-1, len(body))
+			}
+
+			if _, writeErr = o.writer.WriteAt(body, start); writeErr != nil {
+				return writeErr
+			}
+
+			o.checksums.add(index, body)
+
+			return nil
+		})
+
+		switch {
+		case err == nil:
+			return nil
+		case writeErr != nil:
+			return fmt.Errorf("Downloader: cannot write range %d-%d: %w", start, end-1, writeErr)
+		case errors.Is(err, ErrPreconditionFailed):
+			return fmt.Errorf("Downloader: %s changed during the download: %w", o.input.Key, err)
+		case attempt >= o.downloader.options.MaxAttempts || !isRetryableDownloadError(err):
+			return fmt.Errorf("Downloader: range %d-%d: %w", start, end-1, err)
+		}
+	}
+}
+
+// isRetryableDownloadError reports network and server errors, as well as
+// truncated bodies.
+func isRetryableDownloadError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var apiErr *api.Error
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= fasthttp.StatusInternalServerError
+	}
+
+	return true
+}
+
+// rangeChecksums validates the object checksum from the checksums of the
+// downloaded ranges, which are combined when there are several of them.
+type rangeChecksums struct {
+	algorithm ChecksumAlgorithm
+	expected  []byte
+	combiner  crcCombiner
+
+	// Every range is only written by the goroutine downloading it.
+	digests [][]byte
+	sizes   []int64
+}
+
+// newRangeChecksums returns nil when the checksum of the object cannot be
+// validated.
+func newRangeChecksums(checksums Checksums, count int) *rangeChecksums {
+	if count == 0 {
+		return nil
+	}
+
+	for _, checksum := range checksumHeaders {
+		value := checksums.Get(checksum.algorithm)
+		if value == "" {
+			continue
+		}
+
+		// Composite checksums are suffixed by the number of parts.
+		if strings.Contains(value, "-") {
+			return nil
+		}
+
+		expected, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil
+		}
+
+		combiner, ok := checksum.algorithm.crcCombiner()
+		if !ok && count > 1 {
+			return nil
+		}
+
+		return &rangeChecksums{
+			algorithm: checksum.algorithm,
+			expected:  expected,
+			combiner:  combiner,
+			digests:   make([][]byte, count),
+			sizes:     make([]int64, count),
+		}
+	}
+
+	return nil
+}
+
+func (r *rangeChecksums) add(index int, body []byte) {
+	if r == nil {
+		return
+	}
+
+	h, _ := r.algorithm.newHash() // The algorithm comes from checksumHeaders.
+	h.Write(body)
+
+	r.digests[index] = h.Sum(nil)
+	r.sizes[index] = int64(len(body))
+}
+
+func (r *rangeChecksums) check() error {
+	actual := r.digests[0]
+	for index := 1; index < len(r.digests); index++ {
+		actual = r.combiner.combine(actual, r.digests[index], r.sizes[index])
+	}
+
+	if !bytes.Equal(actual, r.expected) {
+		return fmt.Errorf("%w: %s is %q instead of %q", ErrChecksumMismatch, r.algorithm,
+			base64.StdEncoding.EncodeToString(actual), base64.StdEncoding.EncodeToString(r.expected))
+	}
+
+	return nil
+}
@@ -0,0 +1,207 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/lvjp/s3hobby/pkg/s3/api"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+// fakeObject is a single object served with range requests.
+type fakeObject struct {
+	content  string
+	etag     string
+	checksum string
+
+	// failures maps a range to the statuses of its first attempts.
+	failures map[string][]int
+}
+
+// newObjectServer returns a server serving the object.
+func newObjectServer(t *testing.T, object *fakeObject) *fakeServer {
+	t.Helper()
+
+	server := newFakeServer(t)
+	server.on(fasthttp.MethodHead, "", object.head)
+	server.on(fasthttp.MethodGet, "", object.get)
+
+	return server
+}
+
+func (o *fakeObject) headers() []string {
+	return []string{
+		"ETag: " + o.etag,
+		"x-amz-checksum-crc64nvme: " + o.checksum,
+	}
+}
+
+func (o *fakeObject) head(*fasthttp.Request) string {
+	return rawResponse(fasthttp.StatusOK, "", append(o.headers(), fmt.Sprintf("Content-Length: %d", len(o.content)))...)
+}
+
+func (o *fakeObject) get(req *fasthttp.Request) string {
+	if ifMatch := string(req.Header.Peek(api.HeaderIfMatch)); ifMatch != o.etag {
+		return rawResponse(fasthttp.StatusPreconditionFailed, "<Error><Code>PreconditionFailed</Code></Error>")
+	}
+
+	rangeHeader := string(req.Header.Peek(api.HeaderRange))
+	if statuses := o.failures[rangeHeader]; len(statuses) > 0 {
+		o.failures[rangeHeader] = statuses[1:]
+		return rawResponse(statuses[0], "<Error><Code>"+fasthttp.StatusMessage(statuses[0])+"</Code></Error>")
+	}
+
+	var start, end int
+	if _, err := fmt.Sscanf(rangeHeader, "bytes=%d-%d", &start, &end); err != nil {
+		return rawResponse(fasthttp.StatusBadRequest, "<Error><Code>InvalidRange</Code></Error>")
+	}
+
+	end = min(end, len(o.content)-1)
+
+	return rawResponse(fasthttp.StatusPartialContent, o.content[start:end+1],
+		append(o.headers(), fmt.Sprintf("Content-Range: bytes %d-%d/%d", start, end, len(o.content)))...)
+}
+
+// writerAt is an in memory io.WriterAt, distinct ranges may be written
+// concurrently.
+type writerAt []byte
+
+func (w writerAt) WriteAt(p []byte, off int64) (int, error) {
+	return copy(w[off:], p), nil
+}
+
+var testDownloaderOptions = DownloaderOptions{PartSize: 10, Concurrency: 2}
+
+func TestDownloader(t *testing.T) {
+	content := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 3)
+	checksum, err := computeChecksum(ChecksumAlgorithmCRC64NVME, []byte(content))
+	require.NoError(t, err)
+
+	input := &DownloadInput{Bucket: "examplebucket", Key: "example-object"}
+
+	testCases := []struct {
+		name     string
+		checksum string
+		failures map[string][]int
+
+		expectedGets      int
+		expectedValidated ChecksumAlgorithm
+	}{
+		{
+			name:              "full object checksum",
+			checksum:          checksum,
+			expectedGets:      14,
+			expectedValidated: ChecksumAlgorithmCRC64NVME,
+		},
+		{
+			name:         "composite checksum",
+			checksum:     "rosUhgp5mIg=-14",
+			expectedGets: 14,
+		},
+		{
+			name:     "retried ranges",
+			checksum: checksum,
+			failures: map[string][]int{
+				"bytes=0-9":     {fasthttp.StatusServiceUnavailable},
+				"bytes=130-134": {fasthttp.StatusInternalServerError, fasthttp.StatusServiceUnavailable},
+			},
+			expectedGets:      17,
+			expectedValidated: ChecksumAlgorithmCRC64NVME,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			object := &fakeObject{content: content, etag: `"etag"`, checksum: tc.checksum, failures: tc.failures}
+			server := newObjectServer(t, object)
+			downloader := newTestHelper(t, server, NewDownloader, testDownloaderOptions)
+
+			w := make(writerAt, len(content))
+			output, err := downloader.Download(context.Background(), w, input)
+			require.NoError(t, err)
+
+			assert.Equal(t, content, string(w))
+			assert.Equal(t, int64(len(content)), output.ContentLength)
+			assert.Equal(t, `"etag"`, output.ETag)
+			assert.Equal(t, tc.expectedValidated, output.ValidatedChecksum)
+			assert.Len(t, server.requests(fasthttp.MethodGet, ""), tc.expectedGets)
+		})
+	}
+
+	t.Run("checksum mismatch", func(t *testing.T) {
+		object := &fakeObject{content: content, etag: `"etag"`, checksum: base64.StdEncoding.EncodeToString(make([]byte, 8))}
+		server := newObjectServer(t, object)
+		downloader := newTestHelper(t, server, NewDownloader, testDownloaderOptions)
+
+		_, err := downloader.Download(context.Background(), make(writerAt, len(content)), input)
+		require.ErrorIs(t, err, ErrChecksumMismatch)
+	})
+
+	t.Run("object changed", func(t *testing.T) {
+		object := &fakeObject{content: content, etag: `"etag"`, checksum: checksum}
+
+		server := newFakeServer(t)
+		server.on(fasthttp.MethodHead, "", func(req *fasthttp.Request) string {
+			ret := object.head(req)
+			object.etag = `"overwritten"`
+
+			return ret
+		})
+		server.on(fasthttp.MethodGet, "", object.get)
+
+		downloader := newTestHelper(t, server, NewDownloader, DownloaderOptions{PartSize: 10, Concurrency: 1})
+
+		_, err := downloader.Download(context.Background(), make(writerAt, len(content)), input)
+		require.ErrorContains(t, err, "Downloader: example-object changed during the download")
+
+		var apiErr *api.Error
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, fasthttp.StatusPreconditionFailed, apiErr.StatusCode)
+		assert.Len(t, server.requests(fasthttp.MethodGet, ""), 1, "precondition failures must not be retried")
+	})
+
+	t.Run("too many failures", func(t *testing.T) {
+		object := &fakeObject{
+			content:  content,
+			etag:     `"etag"`,
+			checksum: checksum,
+			failures: map[string][]int{"bytes=10-19": {500, 500, 500}},
+		}
+		server := newObjectServer(t, object)
+		downloader := newTestHelper(t, server, NewDownloader, testDownloaderOptions)
+
+		_, err := downloader.Download(context.Background(), make(writerAt, len(content)), input)
+		require.EqualError(t, err, "Downloader: range 10-19: s3: 500 Internal Server Error")
+	})
+
+	t.Run("empty object", func(t *testing.T) {
+		object := &fakeObject{etag: `"etag"`}
+		server := newObjectServer(t, object)
+		downloader := newTestHelper(t, server, NewDownloader, testDownloaderOptions)
+
+		output, err := downloader.Download(context.Background(), writerAt{}, input)
+		require.NoError(t, err)
+		assert.Equal(t, &DownloadOutput{ObjectHeaders: ObjectHeaders{ETag: `"etag"`}}, output)
+		assert.Empty(t, server.requests(fasthttp.MethodGet, ""))
+	})
+
+	t.Run("unknown size", func(t *testing.T) {
+		server := newFakeServer(t)
+		server.on(fasthttp.MethodHead, "", func(*fasthttp.Request) string {
+			// rawResponse always sends a Content-Length.
+			return "HTTP/1.1 200 OK\r\nETag: \"etag\"\r\n\r\n"
+		})
+
+		downloader := newTestHelper(t, server, NewDownloader, DownloaderOptions{})
+
+		_, err := downloader.Download(context.Background(), writerAt{}, input)
+		require.EqualError(t, err, "Downloader: example-object: unknown object size")
+		assert.Len(t, server.httpClient.requests, 1)
+	})
+}
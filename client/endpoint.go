@@ -0,0 +1,82 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/lvjp/s3hobby/pkg/s3/signing/functions"
+
+	"github.com/valyala/fasthttp"
+)
+
+// Directory buckets are named "<base-name>--<zone-id>--x-s3".
+const directoryBucketSuffix = "--x-s3"
+
+// zonalEndpointPrefix starts the host of the zonal endpoints of the directory
+// buckets, after the bucket name.
+const zonalEndpointPrefix = "s3express-"
+
+func isDirectoryBucket(bucket string) bool {
+	return strings.HasSuffix(bucket, directoryBucketSuffix)
+}
+
+// directoryBucketZone extracts the availability zone ID of a directory bucket.
+func directoryBucketZone(bucket string) (string, error) {
+	name := strings.TrimSuffix(bucket, directoryBucketSuffix)
+
+	i := strings.LastIndex(name, "--")
+	if i <= 0 || i+len("--") == len(name) {
+		return "", fmt.Errorf("client: invalid directory bucket name %q", bucket)
+	}
+
+	return name[i+len("--"):], nil
+}
+
+func parseEndpoint(endpoint, region string) (*url.URL, error) {
+	if endpoint == "" {
+		return &url.URL{Scheme: "https", Host: "s3." + region + ".amazonaws.com"}, nil
+	}
+
+	ret, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("client: invalid endpoint: %w", err)
+	}
+
+	if ret.Scheme == "" || ret.Host == "" {
+		return nil, errors.New("client: endpoint must be an absolute URL")
+	}
+
+	ret.Path = strings.TrimSuffix(ret.Path, "/")
+
+	return ret, nil
+}
+
+// setRequestURI addresses the request to the bucket and key, both may be empty.
+// Query parameters can be added afterward. The path is sent as is: once
+// normalized, keys like "a//b", "x/../y" or "c+d" would address another object
+// and no longer match the signed path.
+func (c *Client) setRequestURI(req *fasthttp.Request, bucket, key string) error {
+	scheme, host, path := c.endpoint.Scheme, c.endpoint.Host, c.endpoint.Path
+
+	switch {
+	case bucket == "":
+	case isDirectoryBucket(bucket) && c.options.Endpoint == "":
+		zone, err := directoryBucketZone(bucket)
+		if err != nil {
+			return err
+		}
+
+		host = bucket + "." + zonalEndpointPrefix + zone + "." + c.options.Region + ".amazonaws.com"
+	case c.options.UsePathStyle:
+		path += "/" + bucket
+	default:
+		host = bucket + "." + host
+	}
+
+	req.SetRequestURI(scheme + "://" + host + path + "/" + functions.URIEncode(key, true))
+	req.URI().DisablePathNormalizing = true
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package client
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+
+	"github.com/lvjp/s3hobby/pkg/s3/api"
+
+	"github.com/valyala/fasthttp"
+)
+
+type GetBucketLocationInput struct {
+	Bucket string
+}
+
+type GetBucketLocationOutput struct {
+	// Region is the normalized location constraint: the legacy empty and
+	// "EU" values are reported as us-east-1 and eu-west-1.
+	Region string
+}
+
+// GetBucketLocation returns the region of a bucket, HeadBucket should be
+// preferred since it does not require the bucket owner permissions.
+func (c *Client) GetBucketLocation(ctx context.Context, input *GetBucketLocationInput) (*GetBucketLocationOutput, error) {
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(resp)
+
+	if err := c.setRequestURI(req, input.Bucket, ""); err != nil {
+		return nil, err
+	}
+
+	req.Header.SetMethod(fasthttp.MethodGet)
+	req.URI().QueryArgs().AddNoValue("location")
+
+	if err := c.doBucket(ctx, input.Bucket, req, resp); err != nil {
+		return nil, err
+	}
+
+	var result api.LocationConstraint
+	if err := xml.Unmarshal(resp.Body(), &result); err != nil {
+		return nil, fmt.Errorf("GetBucketLocation: cannot parse response: %w", err)
+	}
+
+	return &GetBucketLocationOutput{
+		Region: normalizeLocationConstraint(result.Value),
+	}, nil
+}
+
+func normalizeLocationConstraint(locationConstraint string) string {
+	switch locationConstraint {
+	case "":
+		return defaultRegion
+	case "EU":
+		return "eu-west-1"
+	default:
+		return locationConstraint
+	}
+}
@@ -0,0 +1,49 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func TestGetBucketLocation(t *testing.T) {
+	testCases := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{
+			name:     "region",
+			body:     `<LocationConstraint xmlns="http://s3.amazonaws.com/doc/2006-03-01/">eu-west-3</LocationConstraint>`,
+			expected: "eu-west-3",
+		},
+		{
+			name:     "empty",
+			body:     `<LocationConstraint xmlns="http://s3.amazonaws.com/doc/2006-03-01/"/>`,
+			expected: "us-east-1",
+		},
+		{
+			name:     "legacy EU",
+			body:     `<LocationConstraint xmlns="http://s3.amazonaws.com/doc/2006-03-01/">EU</LocationConstraint>`,
+			expected: "eu-west-1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, httpClient, _ := newTestClient(t, func(*fasthttp.Request) string {
+				return rawResponse(fasthttp.StatusOK, tc.body)
+			})
+
+			output, err := c.GetBucketLocation(context.Background(), &GetBucketLocationInput{Bucket: "examplebucket"})
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, output.Region)
+
+			require.Len(t, httpClient.requests, 1)
+			assert.True(t, httpClient.requests[0].URI().QueryArgs().Has("location"))
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lvjp/s3hobby/pkg/s3/api"
+
+	"github.com/valyala/fasthttp"
+)
+
+type GetObjectInput struct {
+	Bucket    string
+	Key       string
+	VersionID string
+
+	// PartNumber reads a part of a multipart object, it cannot be combined
+	// with Range.
+	PartNumber int
+	Range      ByteRange
+
+	// IfMatch and IfUnmodifiedSince fail the request with
+	// ErrPreconditionFailed when not met. IfNoneMatch and IfModifiedSince
+	// turn it into a NotModified output.
+	IfMatch           string
+	IfNoneMatch       string
+	IfModifiedSince   time.Time
+	IfUnmodifiedSince time.Time
+
+	ResponseHeaders ResponseHeaders
+
+	// ChecksumMode asks S3 to return the object checksums.
+	ChecksumMode bool
+
+	// SSECustomerKey is the 256 bits key of an object encrypted with SSE-C.
+	SSECustomerKey []byte
+}
+
+type GetObjectOutput struct {
+	ObjectHeaders
+
+	// ContentRange is set for range requests, like "bytes 0-1023/4096".
+	ContentRange string
+
+	// NotModified reports a 304 status caused by If-None-Match or
+	// If-Modified-Since, only the ETag and LastModified headers are then set
+	// and Body is empty.
+	NotModified bool
+
+	Body []byte
+}
+
+// GetObject downloads an object in memory, see Downloader for large objects.
+func (c *Client) GetObject(ctx context.Context, input *GetObjectInput) (*GetObjectOutput, error) {
+	var output *GetObjectOutput
+
+	err := c.getObject(ctx, input, func(resp *fasthttp.Response, notModified bool) error {
+		headers, err := objectHeadersFromResponse(&resp.Header)
+		if err != nil {
+			return fmt.Errorf("GetObject: %w", err)
+		}
+
+		output = &GetObjectOutput{
+			ObjectHeaders: headers,
+			ContentRange:  string(resp.Header.Peek(api.HeaderContentRange)),
+			NotModified:   notModified,
+			Body:          bytes.Clone(resp.Body()),
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return output, nil
+}
+
+// getObject sends a GetObject request and hands the response to handle before
+// its release, which saves a copy of the body.
+func (c *Client) getObject(ctx context.Context, input *GetObjectInput, handle func(resp *fasthttp.Response, notModified bool) error) error {
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(resp)
+
+	err := c.setObjectRequest(req, fasthttp.MethodGet, &objectRequest{
+		bucket:            input.Bucket,
+		key:               input.Key,
+		versionID:         input.VersionID,
+		partNumber:        input.PartNumber,
+		byteRange:         input.Range,
+		ifMatch:           input.IfMatch,
+		ifNoneMatch:       input.IfNoneMatch,
+		ifModifiedSince:   input.IfModifiedSince,
+		ifUnmodifiedSince: input.IfUnmodifiedSince,
+		responseHeaders:   input.ResponseHeaders,
+		checksumMode:      input.ChecksumMode,
+		sseCustomerKey:    input.SSECustomerKey,
+	})
+	if err != nil {
+		return fmt.Errorf("GetObject: %w", err)
+	}
+
+	notModified, err := c.doObjectRequest(ctx, input.Bucket, req, resp)
+	if err != nil {
+		return err
+	}
+
+	return handle(resp, notModified)
+}
@@ -0,0 +1,62 @@
+package client
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+
+	"github.com/lvjp/s3hobby/pkg/s3/api"
+
+	"github.com/valyala/fasthttp"
+)
+
+type GetObjectTaggingInput struct {
+	Bucket    string
+	Key       string
+	VersionID string
+}
+
+type GetObjectTaggingOutput struct {
+	VersionID string
+	TagSet    TagSet
+}
+
+func (c *Client) GetObjectTagging(ctx context.Context, input *GetObjectTaggingInput) (*GetObjectTaggingOutput, error) {
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(resp)
+
+	if err := c.setTaggingRequest(req, fasthttp.MethodGet, input.Bucket, input.Key, input.VersionID); err != nil {
+		return nil, err
+	}
+
+	if err := c.doBucket(ctx, input.Bucket, req, resp); err != nil {
+		return nil, err
+	}
+
+	var result api.TaggingResult
+	if err := xml.Unmarshal(resp.Body(), &result); err != nil {
+		return nil, fmt.Errorf("GetObjectTagging: cannot parse response: %w", err)
+	}
+
+	return &GetObjectTaggingOutput{
+		VersionID: string(resp.Header.Peek(api.HeaderXAmzVersionID)),
+		TagSet:    tagSetFromAPI(result.TagSet),
+	}, nil
+}
+
+// setTaggingRequest prepares the requests on the tags of an object.
+func (c *Client) setTaggingRequest(req *fasthttp.Request, method, bucket, key, versionID string) error {
+	if err := c.setRequestURI(req, bucket, key); err != nil {
+		return err
+	}
+
+	req.Header.SetMethod(method)
+
+	args := req.URI().QueryArgs()
+	args.AddNoValue("tagging")
+	addQueryArg(args, "versionId", versionID)
+
+	return nil
+}
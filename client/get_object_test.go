@@ -0,0 +1,265 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lvjp/s3hobby/pkg/s3/api"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+var objectResponseHeaders = []string{
+	"Content-Type: text/plain",
+	`ETag: "fba9dede5f27731c9771645a39863328"`,
+	"Last-Modified: Sun, 01 Jan 2006 12:00:00 GMT",
+	"x-amz-version-id: 3HL4kqtJlcpXroDTDmJ+rmSpXd3dIbrHY+MTRCxf3vjVBH40Nr8X8gdRQBpUMLUo",
+	"x-amz-storage-class: STANDARD",
+	"x-amz-meta-Author: me",
+	"x-amz-checksum-crc32: y/Q5Jg==",
+	"x-amz-checksum-type: FULL_OBJECT",
+}
+
+var expectedObjectHeaders = ObjectHeaders{
+	ContentType:  "text/plain",
+	ETag:         `"fba9dede5f27731c9771645a39863328"`,
+	LastModified: time.Date(2006, time.January, 1, 12, 0, 0, 0, time.UTC),
+	VersionID:    "3HL4kqtJlcpXroDTDmJ+rmSpXd3dIbrHY+MTRCxf3vjVBH40Nr8X8gdRQBpUMLUo",
+	StorageClass: "STANDARD",
+	Metadata:     map[string]string{"author": "me"},
+	Checksums:    Checksums{CRC32: "y/Q5Jg=="},
+	ChecksumType: ChecksumTypeFullObject,
+}
+
+func TestHeadObject(t *testing.T) {
+	c, httpClient, _ := newTestClient(t, func(*fasthttp.Request) string {
+		return rawResponse(fasthttp.StatusOK, "", append(objectResponseHeaders, "Content-Length: 434234", "x-amz-mp-parts-count: 3")...)
+	})
+
+	output, err := c.HeadObject(context.Background(), &HeadObjectInput{
+		Bucket:       "examplebucket",
+		Key:          "example-object",
+		VersionID:    "VERSION",
+		ChecksumMode: true,
+	})
+	require.NoError(t, err)
+
+	expected := expectedObjectHeaders
+	expected.ContentLength = 434234
+	expected.PartsCount = 3
+	assert.Equal(t, &HeadObjectOutput{ObjectHeaders: expected}, output)
+
+	require.Len(t, httpClient.requests, 1)
+	sent := httpClient.requests[0]
+	assert.Equal(t, fasthttp.MethodHead, string(sent.Header.Method()))
+	assert.Equal(t, "versionId=VERSION", sent.URI().QueryArgs().String())
+	assert.Equal(t, "ENABLED", string(sent.Header.Peek(api.HeaderXAmzChecksumMode)))
+}
+
+func TestGetObject(t *testing.T) {
+	c, httpClient, _ := newTestClient(t, func(*fasthttp.Request) string {
+		return rawResponse(fasthttp.StatusPartialContent, "12345", append(objectResponseHeaders, "Content-Range: bytes 0-4/9")...)
+	})
+
+	output, err := c.GetObject(context.Background(), &GetObjectInput{
+		Bucket:  "examplebucket",
+		Key:     "example-object",
+		Range:   NewByteRange(0, 4),
+		IfMatch: `"fba9dede5f27731c9771645a39863328"`,
+	})
+	require.NoError(t, err)
+
+	expected := expectedObjectHeaders
+	expected.ContentLength = 5
+	assert.Equal(t, &GetObjectOutput{
+		ObjectHeaders: expected,
+		ContentRange:  "bytes 0-4/9",
+		Body:          []byte("12345"),
+	}, output)
+
+	require.Len(t, httpClient.requests, 1)
+	sent := httpClient.requests[0]
+	assert.Equal(t, fasthttp.MethodGet, string(sent.Header.Method()))
+	assert.Equal(t, "bytes=0-4", string(sent.Header.Peek(api.HeaderRange)))
+	assert.Equal(t, `"fba9dede5f27731c9771645a39863328"`, string(sent.Header.Peek(api.HeaderIfMatch)))
+	assert.Empty(t, sent.Header.Peek(api.HeaderXAmzChecksumMode))
+}
+
+func TestGetObjectOptions(t *testing.T) {
+	c, httpClient, _ := newTestClient(t, func(*fasthttp.Request) string {
+		return rawResponse(fasthttp.StatusOK, "1", append(objectResponseHeaders,
+			"Cache-Control: no-cache",
+			"Content-Disposition: attachment; filename=example.txt",
+			"Content-Encoding: identity",
+			"Content-Language: fr",
+			"Expires: Thu, 01 Dec 1994 16:00:00 GMT",
+			"x-amz-mp-parts-count: 3")...)
+	})
+
+	output, err := c.GetObject(context.Background(), &GetObjectInput{
+		Bucket:            "examplebucket",
+		Key:               "example-object",
+		PartNumber:        2,
+		IfNoneMatch:       `"etag"`,
+		IfModifiedSince:   time.Date(2006, time.January, 1, 12, 0, 0, 0, time.UTC),
+		IfUnmodifiedSince: time.Date(2007, time.January, 1, 13, 0, 0, 0, time.FixedZone("CET", 3600)),
+		ResponseHeaders: ResponseHeaders{
+			CacheControl:       "no-cache",
+			ContentDisposition: "attachment; filename=example.txt",
+			ContentEncoding:    "identity",
+			ContentLanguage:    "fr",
+			ContentType:        "text/plain",
+			Expires:            time.Date(1994, time.December, 1, 16, 0, 0, 0, time.UTC),
+		},
+	})
+	require.NoError(t, err)
+
+	assert.False(t, output.NotModified)
+	assert.Equal(t, "no-cache", output.CacheControl)
+	assert.Equal(t, "attachment; filename=example.txt", output.ContentDisposition)
+	assert.Equal(t, "identity", output.ContentEncoding)
+	assert.Equal(t, "fr", output.ContentLanguage)
+	assert.Equal(t, "Thu, 01 Dec 1994 16:00:00 GMT", output.Expires)
+	assert.Equal(t, 3, output.PartsCount)
+
+	require.Len(t, httpClient.requests, 1)
+	sent := httpClient.requests[0]
+	assert.Equal(t, "partNumber=2"+
+		"&response-cache-control=no-cache"+
+		"&response-content-disposition=attachment%3B+filename%3Dexample.txt"+
+		"&response-content-encoding=identity"+
+		"&response-content-language=fr"+
+		"&response-content-type=text%2Fplain"+
+		"&response-expires=Thu%2C+01+Dec+1994+16%3A00%3A00+GMT", sent.URI().QueryArgs().String())
+	assert.Empty(t, sent.Header.Peek(api.HeaderRange))
+	assert.Equal(t, `"etag"`, string(sent.Header.Peek(api.HeaderIfNoneMatch)))
+	assert.Equal(t, "Sun, 01 Jan 2006 12:00:00 GMT", string(sent.Header.Peek(api.HeaderIfModifiedSince)))
+	assert.Equal(t, "Mon, 01 Jan 2007 12:00:00 GMT", string(sent.Header.Peek(api.HeaderIfUnmodifiedSince)))
+}
+
+func TestGetObjectConditions(t *testing.T) {
+	input := &GetObjectInput{Bucket: "examplebucket", Key: "example-object", IfNoneMatch: `"fba9dede5f27731c9771645a39863328"`}
+
+	t.Run("not modified", func(t *testing.T) {
+		c, _, _ := newTestClient(t, func(*fasthttp.Request) string {
+			return rawResponse(fasthttp.StatusNotModified, "",
+				`ETag: "fba9dede5f27731c9771645a39863328"`,
+				"Last-Modified: Sun, 01 Jan 2006 12:00:00 GMT")
+		})
+
+		output, err := c.GetObject(context.Background(), input)
+		require.NoError(t, err)
+
+		assert.Equal(t, &GetObjectOutput{
+			ObjectHeaders: ObjectHeaders{
+				ETag:         `"fba9dede5f27731c9771645a39863328"`,
+				LastModified: time.Date(2006, time.January, 1, 12, 0, 0, 0, time.UTC),
+			},
+			NotModified: true,
+		}, output)
+
+		head, err := c.HeadObject(context.Background(), &HeadObjectInput{Bucket: input.Bucket, Key: input.Key, IfNoneMatch: input.IfNoneMatch})
+		require.NoError(t, err)
+		assert.True(t, head.NotModified)
+		assert.Equal(t, `"fba9dede5f27731c9771645a39863328"`, head.ETag)
+	})
+
+	t.Run("precondition failed", func(t *testing.T) {
+		c, _, _ := newTestClient(t, func(req *fasthttp.Request) string {
+			if req.Header.IsHead() {
+				return rawResponse(fasthttp.StatusPreconditionFailed, "")
+			}
+
+			return rawResponse(fasthttp.StatusPreconditionFailed, "<Error><Code>PreconditionFailed</Code></Error>")
+		})
+
+		_, err := c.GetObject(context.Background(), input)
+		require.ErrorIs(t, err, ErrPreconditionFailed)
+
+		var apiErr *api.Error
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, "PreconditionFailed", apiErr.Code)
+
+		_, err = c.HeadObject(context.Background(), &HeadObjectInput{Bucket: input.Bucket, Key: input.Key, IfMatch: `"other"`})
+		require.ErrorIs(t, err, ErrPreconditionFailed)
+	})
+
+	t.Run("other errors", func(t *testing.T) {
+		c, _, _ := newTestClient(t, func(*fasthttp.Request) string {
+			return rawResponse(fasthttp.StatusNotFound, "<Error><Code>NoSuchKey</Code></Error>")
+		})
+
+		_, err := c.GetObject(context.Background(), input)
+		require.EqualError(t, err, "s3: 404 NoSuchKey")
+		require.NotErrorIs(t, err, ErrPreconditionFailed)
+	})
+}
+
+func TestGetObjectInvalid(t *testing.T) {
+	c, httpClient, _ := newTestClient(t, nil)
+
+	testCases := []struct {
+		name     string
+		input    *GetObjectInput
+		expected string
+	}{
+		{
+			name:     "part number and range",
+			input:    &GetObjectInput{Bucket: "examplebucket", Key: "example-object", PartNumber: 1, Range: ByteRangeFrom(0)},
+			expected: "GetObject: part number and range are mutually exclusive",
+		},
+		{
+			name:     "part number",
+			input:    &GetObjectInput{Bucket: "examplebucket", Key: "example-object", PartNumber: MaxPartNumber + 1},
+			expected: "GetObject: part number must be between 1 and 10000",
+		},
+		{
+			name:     "range",
+			input:    &GetObjectInput{Bucket: "examplebucket", Key: "example-object", Range: NewByteRange(10, 9)},
+			expected: "GetObject: invalid range from 10 to 9",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := c.GetObject(context.Background(), tc.input)
+			require.EqualError(t, err, tc.expected)
+		})
+	}
+
+	assert.Empty(t, httpClient.requests)
+}
+
+func TestByteRange(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    ByteRange
+		expected string
+		err      string
+	}{
+		{name: "zero", expected: ""},
+		{name: "bounded", input: NewByteRange(0, 1023), expected: "bytes=0-1023"},
+		{name: "single byte", input: NewByteRange(42, 42), expected: "bytes=42-42"},
+		{name: "open ended", input: ByteRangeFrom(1024), expected: "bytes=1024-"},
+		{name: "suffix", input: ByteRangeSuffix(500), expected: "bytes=-500"},
+		{name: "negative start", input: NewByteRange(-1, 10), expected: "bytes=-1-10", err: "invalid range from -1 to 10"},
+		{name: "negative open start", input: ByteRangeFrom(-1), expected: "bytes=-1-", err: "invalid range from -1"},
+		{name: "empty suffix", input: ByteRangeSuffix(0), expected: "bytes=-0", err: "invalid range of the last 0 bytes"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.input.String())
+			assert.Equal(t, tc.expected == "", tc.input.IsZero())
+
+			if tc.err == "" {
+				require.NoError(t, tc.input.validate())
+			} else {
+				require.EqualError(t, tc.input.validate(), tc.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package client
+
+import (
+	"context"
+
+	"github.com/lvjp/s3hobby/pkg/s3/api"
+
+	"github.com/valyala/fasthttp"
+)
+
+type HeadBucketInput struct {
+	Bucket string
+}
+
+type HeadBucketOutput struct {
+	// BucketRegion is the region where the bucket is located.
+	BucketRegion string
+
+	// AccessPointAlias reports whether Bucket is an access point alias.
+	AccessPointAlias bool
+}
+
+// HeadBucket checks the existence of a bucket and the permission to access it.
+// Being a HEAD request, failures are reported without error code details. When
+// the bucket is in another region, the returned *api.Error holds it in its
+// BucketRegion field.
+func (c *Client) HeadBucket(ctx context.Context, input *HeadBucketInput) (*HeadBucketOutput, error) {
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(resp)
+
+	if err := c.setRequestURI(req, input.Bucket, ""); err != nil {
+		return nil, err
+	}
+
+	req.Header.SetMethod(fasthttp.MethodHead)
+
+	if err := c.doBucket(ctx, input.Bucket, req, resp); err != nil {
+		return nil, err
+	}
+
+	return &HeadBucketOutput{
+		BucketRegion:     string(resp.Header.Peek(api.HeaderXAmzBucketRegion)),
+		AccessPointAlias: string(resp.Header.Peek(api.HeaderXAmzAccessPointAlias)) == "true",
+	}, nil
+}
@@ -0,0 +1,173 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lvjp/s3hobby/pkg/s3/api"
+
+	"github.com/valyala/fasthttp"
+)
+
+type HeadObjectInput struct {
+	Bucket    string
+	Key       string
+	VersionID string
+
+	// PartNumber reads the attributes of a part of a multipart object, it
+	// cannot be combined with Range.
+	PartNumber int
+	Range      ByteRange
+
+	// The conditions are evaluated by S3 like the ones of GetObject.
+	IfMatch           string
+	IfNoneMatch       string
+	IfModifiedSince   time.Time
+	IfUnmodifiedSince time.Time
+
+	ResponseHeaders ResponseHeaders
+
+	// ChecksumMode asks S3 to return the object checksums.
+	ChecksumMode bool
+
+	// SSECustomerKey is the 256 bits key of an object encrypted with SSE-C.
+	SSECustomerKey []byte
+}
+
+// ObjectHeaders holds the object attributes sent as headers by HeadObject
+// and GetObject.
+type ObjectHeaders struct {
+	ContentLength int64
+	ContentType   string
+	ETag          string
+
+	CacheControl       string
+	ContentDisposition string
+	ContentEncoding    string
+	ContentLanguage    string
+
+	// Expires is kept verbatim, S3 does not validate it.
+	Expires string
+
+	LastModified time.Time
+	VersionID    string
+	StorageClass string
+
+	// Metadata holds the x-amz-meta-* headers, keyed by their lower case
+	// suffix.
+	Metadata map[string]string
+
+	// Checksums are only returned when ChecksumMode is set. The checksums of
+	// objects with the composite type are suffixed by their number of parts.
+	Checksums    Checksums
+	ChecksumType ChecksumType
+
+	// PartsCount is the number of parts of multipart objects, zero otherwise.
+	PartsCount int
+}
+
+type HeadObjectOutput struct {
+	ObjectHeaders
+
+	// NotModified reports a 304 status caused by If-None-Match or
+	// If-Modified-Since, only the ETag and LastModified headers are then set.
+	NotModified bool
+}
+
+// HeadObject returns the attributes of an object without its content. Being a
+// HEAD request, failures are reported without error code details. An unmet
+// If-Match or If-Unmodified-Since condition fails with ErrPreconditionFailed.
+func (c *Client) HeadObject(ctx context.Context, input *HeadObjectInput) (*HeadObjectOutput, error) {
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(resp)
+
+	err := c.setObjectRequest(req, fasthttp.MethodHead, &objectRequest{
+		bucket:            input.Bucket,
+		key:               input.Key,
+		versionID:         input.VersionID,
+		partNumber:        input.PartNumber,
+		byteRange:         input.Range,
+		ifMatch:           input.IfMatch,
+		ifNoneMatch:       input.IfNoneMatch,
+		ifModifiedSince:   input.IfModifiedSince,
+		ifUnmodifiedSince: input.IfUnmodifiedSince,
+		responseHeaders:   input.ResponseHeaders,
+		checksumMode:      input.ChecksumMode,
+		sseCustomerKey:    input.SSECustomerKey,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("HeadObject: %w", err)
+	}
+
+	notModified, err := c.doObjectRequest(ctx, input.Bucket, req, resp)
+	if err != nil {
+		return nil, err
+	}
+
+	headers, err := objectHeadersFromResponse(&resp.Header)
+	if err != nil {
+		return nil, fmt.Errorf("HeadObject: %w", err)
+	}
+
+	return &HeadObjectOutput{ObjectHeaders: headers, NotModified: notModified}, nil
+}
+
+func objectHeadersFromResponse(header *fasthttp.ResponseHeader) (ObjectHeaders, error) {
+	// Responses without content, like 304 ones, have no Content-Type.
+	header.SetNoDefaultContentType(true)
+
+	ret := ObjectHeaders{
+		ContentLength: int64(header.ContentLength()),
+		ContentType:   string(header.ContentType()),
+		ETag:          string(header.Peek(api.HeaderETag)),
+
+		CacheControl:       string(header.Peek(api.HeaderCacheControl)),
+		ContentDisposition: string(header.Peek(api.HeaderContentDisposition)),
+		ContentEncoding:    string(header.ContentEncoding()),
+		ContentLanguage:    string(header.Peek(api.HeaderContentLanguage)),
+		Expires:            string(header.Peek(api.HeaderExpires)),
+
+		VersionID:    string(header.Peek(api.HeaderXAmzVersionID)),
+		StorageClass: string(header.Peek(api.HeaderXAmzStorageClass)),
+		Checksums:    checksumsFromHeaders(header),
+		ChecksumType: ChecksumType(header.Peek(api.HeaderXAmzChecksumType)),
+	}
+
+	if value := header.Peek(api.HeaderLastModified); len(value) > 0 {
+		lastModified, err := fasthttp.ParseHTTPDate(value)
+		if err != nil {
+			return ObjectHeaders{}, fmt.Errorf("invalid %s header: %w", api.HeaderLastModified, err)
+		}
+
+		ret.LastModified = lastModified.UTC()
+	}
+
+	if value := header.Peek(api.HeaderXAmzMpPartsCount); len(value) > 0 {
+		partsCount, err := strconv.Atoi(string(value))
+		if err != nil {
+			return ObjectHeaders{}, fmt.Errorf("invalid %s header: %w", api.HeaderXAmzMpPartsCount, err)
+		}
+
+		ret.PartsCount = partsCount
+	}
+
+	for key, value := range header.All() {
+		name, found := strings.CutPrefix(strings.ToLower(string(key)), api.HeaderXAmzMetaPrefix)
+		if !found {
+			continue
+		}
+
+		if ret.Metadata == nil {
+			ret.Metadata = make(map[string]string)
+		}
+
+		ret.Metadata[name] = string(value)
+	}
+
+	return ret, nil
+}
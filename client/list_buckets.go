@@ -0,0 +1,119 @@
+package client
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"iter"
+	"strconv"
+	"time"
+
+	"github.com/lvjp/s3hobby/pkg/s3/api"
+
+	"github.com/valyala/fasthttp"
+)
+
+type Bucket struct {
+	Name         string
+	CreationDate time.Time
+
+	// BucketRegion is only returned by paginated listings.
+	BucketRegion string
+}
+
+type ListBucketsInput struct {
+	Prefix            string
+	BucketRegion      string
+	ContinuationToken string
+
+	// MaxBuckets enables the pagination, all buckets are listed at once
+	// otherwise.
+	MaxBuckets int
+}
+
+type ListBucketsOutput struct {
+	Buckets []Bucket
+	Owner   *Owner
+	Prefix  string
+
+	// ContinuationToken is set when more buckets remain to be listed.
+	ContinuationToken string
+}
+
+func (c *Client) ListBuckets(ctx context.Context, input *ListBucketsInput) (*ListBucketsOutput, error) {
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(resp)
+
+	if err := c.setRequestURI(req, "", ""); err != nil {
+		return nil, err
+	}
+
+	req.Header.SetMethod(fasthttp.MethodGet)
+
+	args := req.URI().QueryArgs()
+	addQueryArg(args, "prefix", input.Prefix)
+	addQueryArg(args, "bucket-region", input.BucketRegion)
+	addQueryArg(args, "continuation-token", input.ContinuationToken)
+
+	if input.MaxBuckets > 0 {
+		args.Add("max-buckets", strconv.Itoa(input.MaxBuckets))
+	}
+
+	if err := c.Do(ctx, req, resp); err != nil {
+		return nil, err
+	}
+
+	var result api.ListAllMyBucketsResult
+	if err := xml.Unmarshal(resp.Body(), &result); err != nil {
+		return nil, fmt.Errorf("ListBuckets: cannot parse response: %w", err)
+	}
+
+	output := &ListBucketsOutput{
+		Buckets:           make([]Bucket, 0, len(result.Buckets)),
+		Prefix:            result.Prefix,
+		ContinuationToken: result.ContinuationToken,
+		Owner:             ownerFromAPI(result.Owner),
+	}
+
+	for _, bucket := range result.Buckets {
+		output.Buckets = append(output.Buckets, Bucket{
+			Name:         bucket.Name,
+			CreationDate: bucket.CreationDate,
+			BucketRegion: bucket.BucketRegion,
+		})
+	}
+
+	return output, nil
+}
+
+// ListBucketsPages iterates over the pages of a listing by following the
+// continuation tokens. The input is not modified.
+func (c *Client) ListBucketsPages(ctx context.Context, input *ListBucketsInput) iter.Seq2[*ListBucketsOutput, error] {
+	return func(yield func(*ListBucketsOutput, error) bool) {
+		pageInput := *input
+
+		for {
+			output, err := c.ListBuckets(ctx, &pageInput)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			if !yield(output, nil) || output.ContinuationToken == "" {
+				return
+			}
+
+			pageInput.ContinuationToken = output.ContinuationToken
+		}
+	}
+}
+
+// Buckets iterates over every bucket of a listing, the iteration stops after
+// the first error.
+func (c *Client) Buckets(ctx context.Context, input *ListBucketsInput) iter.Seq2[Bucket, error] {
+	return flattenPages(c.ListBucketsPages(ctx, input), func(output *ListBucketsOutput) []Bucket {
+		return output.Buckets
+	})
+}
@@ -0,0 +1,76 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func TestListBuckets(t *testing.T) {
+	c, httpClient, _ := newTestClient(t, func(*fasthttp.Request) string {
+		return rawResponse(fasthttp.StatusOK, `<?xml version="1.0" encoding="UTF-8"?>
+<ListAllMyBucketsResult>
+  <Buckets>
+    <Bucket>
+      <BucketRegion>eu-west-3</BucketRegion>
+      <CreationDate>2019-12-11T23:32:47.000Z</CreationDate>
+      <Name>examplebucket</Name>
+    </Bucket>
+  </Buckets>
+  <Owner>
+    <DisplayName>Account+Name</DisplayName>
+    <ID>AIDACKCEVSQ6C2EXAMPLE</ID>
+  </Owner>
+  <ContinuationToken>token</ContinuationToken>
+  <Prefix>example</Prefix>
+</ListAllMyBucketsResult>`)
+	})
+
+	output, err := c.ListBuckets(context.Background(), &ListBucketsInput{
+		Prefix:       "example",
+		BucketRegion: "eu-west-3",
+		MaxBuckets:   1,
+	})
+	require.NoError(t, err)
+
+	require.Len(t, httpClient.requests, 1)
+	sent := httpClient.requests[0]
+	assert.Equal(t, "s3.us-east-1.amazonaws.com", string(sent.Host()))
+	assert.Equal(t, "prefix=example&bucket-region=eu-west-3&max-buckets=1", sent.URI().QueryArgs().String())
+
+	assert.Equal(t, &ListBucketsOutput{
+		Buckets: []Bucket{
+			{
+				Name:         "examplebucket",
+				CreationDate: time.Date(2019, time.December, 11, 23, 32, 47, 0, time.UTC),
+				BucketRegion: "eu-west-3",
+			},
+		},
+		Owner:             &Owner{ID: "AIDACKCEVSQ6C2EXAMPLE", DisplayName: "Account+Name"},
+		Prefix:            "example",
+		ContinuationToken: "token",
+	}, output)
+}
+
+func TestBuckets(t *testing.T) {
+	c, httpClient, _ := newTestClient(t, func(req *fasthttp.Request) string {
+		if string(req.URI().QueryArgs().Peek("continuation-token")) == "next" {
+			return rawResponse(fasthttp.StatusOK, "<ListAllMyBucketsResult><Buckets><Bucket><Name>c</Name></Bucket></Buckets></ListAllMyBucketsResult>")
+		}
+
+		return rawResponse(fasthttp.StatusOK, "<ListAllMyBucketsResult><Buckets><Bucket><Name>a</Name></Bucket><Bucket><Name>b</Name></Bucket></Buckets><ContinuationToken>next</ContinuationToken></ListAllMyBucketsResult>")
+	})
+
+	var names []string
+	for bucket, err := range c.Buckets(context.Background(), &ListBucketsInput{MaxBuckets: 2}) {
+		require.NoError(t, err)
+		names = append(names, bucket.Name)
+	}
+
+	assert.Equal(t, []string{"a", "b", "c"}, names)
+	assert.Len(t, httpClient.requests, 2)
+}
@@ -0,0 +1,165 @@
+package client
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"iter"
+	"strconv"
+	"time"
+
+	"github.com/lvjp/s3hobby/pkg/s3/api"
+
+	"github.com/valyala/fasthttp"
+)
+
+type MultipartUpload struct {
+	Key       string
+	UploadID  string
+	Initiated time.Time
+
+	StorageClass      string
+	ChecksumAlgorithm ChecksumAlgorithm
+	ChecksumType      ChecksumType
+
+	Owner     *Owner
+	Initiator *Owner
+}
+
+type ListMultipartUploadsInput struct {
+	Bucket string
+
+	Prefix         string
+	Delimiter      string
+	KeyMarker      string
+	UploadIDMarker string
+
+	// MaxUploads defaults to 1000 on the server side, which is also its maximum.
+	MaxUploads int
+
+	EncodingType EncodingType
+}
+
+// ListMultipartUploadsOutput holds one page of results, keys and prefixes are
+// already decoded when EncodingTypeURL was requested.
+type ListMultipartUploadsOutput struct {
+	Bucket    string
+	Prefix    string
+	Delimiter string
+
+	IsTruncated        bool
+	KeyMarker          string
+	UploadIDMarker     string
+	NextKeyMarker      string
+	NextUploadIDMarker string
+
+	Uploads        []MultipartUpload
+	CommonPrefixes []string
+}
+
+// ListMultipartUploads lists one page of the in-progress multipart uploads of
+// a bucket, see MultipartUploads to iterate over every upload.
+func (c *Client) ListMultipartUploads(ctx context.Context, input *ListMultipartUploadsInput) (*ListMultipartUploadsOutput, error) {
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(resp)
+
+	if err := c.setRequestURI(req, input.Bucket, ""); err != nil {
+		return nil, err
+	}
+
+	req.Header.SetMethod(fasthttp.MethodGet)
+
+	args := req.URI().QueryArgs()
+	args.AddNoValue("uploads")
+	addQueryArg(args, "prefix", input.Prefix)
+	addQueryArg(args, "delimiter", input.Delimiter)
+	addQueryArg(args, "key-marker", input.KeyMarker)
+	addQueryArg(args, "upload-id-marker", input.UploadIDMarker)
+	addQueryArg(args, "encoding-type", string(input.EncodingType))
+
+	if input.MaxUploads > 0 {
+		args.Add("max-uploads", strconv.Itoa(input.MaxUploads))
+	}
+
+	if err := c.doBucket(ctx, input.Bucket, req, resp); err != nil {
+		return nil, err
+	}
+
+	var result api.ListMultipartUploadsResult
+	if err := xml.Unmarshal(resp.Body(), &result); err != nil {
+		return nil, fmt.Errorf("ListMultipartUploads: cannot parse response: %w", err)
+	}
+
+	decoder := keyDecoder{encodingType: result.EncodingType}
+
+	output := &ListMultipartUploadsOutput{
+		Bucket:             result.Bucket,
+		Prefix:             decoder.decode(result.Prefix),
+		Delimiter:          decoder.decode(result.Delimiter),
+		IsTruncated:        result.IsTruncated,
+		KeyMarker:          decoder.decode(result.KeyMarker),
+		UploadIDMarker:     result.UploadIDMarker,
+		NextKeyMarker:      decoder.decode(result.NextKeyMarker),
+		NextUploadIDMarker: result.NextUploadIDMarker,
+		Uploads:            make([]MultipartUpload, 0, len(result.Uploads)),
+		CommonPrefixes:     decoder.commonPrefixes(result.CommonPrefixes),
+	}
+
+	for _, upload := range result.Uploads {
+		output.Uploads = append(output.Uploads, MultipartUpload{
+			Key:               decoder.decode(upload.Key),
+			UploadID:          upload.UploadID,
+			Initiated:         upload.Initiated,
+			StorageClass:      upload.StorageClass,
+			ChecksumAlgorithm: ChecksumAlgorithm(upload.ChecksumAlgorithm),
+			ChecksumType:      ChecksumType(upload.ChecksumType),
+			Owner:             ownerFromAPI(upload.Owner),
+			Initiator:         ownerFromAPI(upload.Initiator),
+		})
+	}
+
+	if err := decoder.err; err != nil {
+		return nil, fmt.Errorf("ListMultipartUploads: %w", err)
+	}
+
+	return output, nil
+}
+
+// ListMultipartUploadsPages iterates over the pages of uploads by following
+// the key and upload ID markers. The input is not modified.
+func (c *Client) ListMultipartUploadsPages(ctx context.Context, input *ListMultipartUploadsInput) iter.Seq2[*ListMultipartUploadsOutput, error] {
+	return func(yield func(*ListMultipartUploadsOutput, error) bool) {
+		pageInput := *input
+
+		for {
+			output, err := c.ListMultipartUploads(ctx, &pageInput)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			if !yield(output, nil) || !output.IsTruncated {
+				return
+			}
+
+			if output.NextKeyMarker == "" && output.NextUploadIDMarker == "" {
+				yield(nil, errors.New("ListMultipartUploads: truncated listing without next markers"))
+				return
+			}
+
+			pageInput.KeyMarker = output.NextKeyMarker
+			pageInput.UploadIDMarker = output.NextUploadIDMarker
+		}
+	}
+}
+
+// MultipartUploads iterates over every in-progress multipart upload, the
+// iteration stops after the first error.
+func (c *Client) MultipartUploads(ctx context.Context, input *ListMultipartUploadsInput) iter.Seq2[MultipartUpload, error] {
+	return flattenPages(c.ListMultipartUploadsPages(ctx, input), func(output *ListMultipartUploadsOutput) []MultipartUpload {
+		return output.Uploads
+	})
+}
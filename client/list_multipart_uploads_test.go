@@ -0,0 +1,124 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func TestListMultipartUploads(t *testing.T) {
+	c, httpClient, _ := newTestClient(t, func(*fasthttp.Request) string {
+		return rawResponse(fasthttp.StatusOK, `<?xml version="1.0" encoding="UTF-8"?>
+<ListMultipartUploadsResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+  <Bucket>examplebucket</Bucket>
+  <KeyMarker></KeyMarker>
+  <UploadIdMarker></UploadIdMarker>
+  <NextKeyMarker>photos%2Fmy+photo%E2%82%AC.jpg</NextKeyMarker>
+  <NextUploadIdMarker>NEXT</NextUploadIdMarker>
+  <Prefix>photos%2F</Prefix>
+  <Delimiter>%2F</Delimiter>
+  <EncodingType>url</EncodingType>
+  <MaxUploads>2</MaxUploads>
+  <IsTruncated>true</IsTruncated>
+  <Upload>
+    <Key>photos%2Fmy+photo%E2%82%AC.jpg</Key>
+    <UploadId>UPLOAD</UploadId>
+    <Initiator>
+      <ID>arn:aws:iam::111122223333:user/user1-11111a31-17b5-4fb7-9df5-b111111f13de</ID>
+      <DisplayName>user1-11111a31-17b5-4fb7-9df5-b111111f13de</DisplayName>
+    </Initiator>
+    <Owner>
+      <ID>75aa57f09aa0c8caeab4f8c24e99d10f8e7faeebf76c078efc7c6caea54ba06a</ID>
+      <DisplayName>OwnerDisplayName</DisplayName>
+    </Owner>
+    <StorageClass>STANDARD</StorageClass>
+    <ChecksumAlgorithm>CRC64NVME</ChecksumAlgorithm>
+    <ChecksumType>FULL_OBJECT</ChecksumType>
+    <Initiated>2010-11-10T20:48:33.000Z</Initiated>
+  </Upload>
+  <CommonPrefixes>
+    <Prefix>photos%2F2006%2F</Prefix>
+  </CommonPrefixes>
+</ListMultipartUploadsResult>`)
+	})
+
+	output, err := c.ListMultipartUploads(context.Background(), &ListMultipartUploadsInput{
+		Bucket:       "examplebucket",
+		Prefix:       "photos/",
+		Delimiter:    "/",
+		MaxUploads:   2,
+		EncodingType: EncodingTypeURL,
+	})
+	require.NoError(t, err)
+
+	require.Len(t, httpClient.requests, 1)
+	sent := httpClient.requests[0]
+	assert.Equal(t, fasthttp.MethodGet, string(sent.Header.Method()))
+	assert.Equal(t, "uploads&prefix=photos%2F&delimiter=%2F&encoding-type=url&max-uploads=2", sent.URI().QueryArgs().String())
+
+	assert.Equal(t, &ListMultipartUploadsOutput{
+		Bucket:             "examplebucket",
+		Prefix:             "photos/",
+		Delimiter:          "/",
+		IsTruncated:        true,
+		NextKeyMarker:      "photos/my photo€.jpg",
+		NextUploadIDMarker: "NEXT",
+		Uploads: []MultipartUpload{
+			{
+				Key:               "photos/my photo€.jpg",
+				UploadID:          "UPLOAD",
+				Initiated:         time.Date(2010, time.November, 10, 20, 48, 33, 0, time.UTC),
+				StorageClass:      "STANDARD",
+				ChecksumAlgorithm: ChecksumAlgorithmCRC64NVME,
+				ChecksumType:      ChecksumTypeFullObject,
+				Owner: &Owner{
+					ID:          "75aa57f09aa0c8caeab4f8c24e99d10f8e7faeebf76c078efc7c6caea54ba06a",
+					DisplayName: "OwnerDisplayName",
+				},
+				Initiator: &Owner{
+					ID:          "arn:aws:iam::111122223333:user/user1-11111a31-17b5-4fb7-9df5-b111111f13de",
+					DisplayName: "user1-11111a31-17b5-4fb7-9df5-b111111f13de",
+				},
+			},
+		},
+		CommonPrefixes: []string{"photos/2006/"},
+	}, output)
+}
+
+func TestMultipartUploads(t *testing.T) {
+	c, httpClient, _ := newTestClient(t, func(req *fasthttp.Request) string {
+		args := req.URI().QueryArgs()
+		if !args.Has("key-marker") {
+			return rawResponse(fasthttp.StatusOK, `<ListMultipartUploadsResult>
+  <IsTruncated>true</IsTruncated>
+  <NextKeyMarker>a</NextKeyMarker>
+  <NextUploadIdMarker>2</NextUploadIdMarker>
+  <Upload><Key>a</Key><UploadId>1</UploadId></Upload>
+  <Upload><Key>a</Key><UploadId>2</UploadId></Upload>
+</ListMultipartUploadsResult>`)
+		}
+
+		assert.Equal(t, "a", string(args.Peek("key-marker")))
+		assert.Equal(t, "2", string(args.Peek("upload-id-marker")))
+
+		return rawResponse(fasthttp.StatusOK, `<ListMultipartUploadsResult>
+  <Upload><Key>b</Key><UploadId>3</UploadId></Upload>
+</ListMultipartUploadsResult>`)
+	})
+
+	input := &ListMultipartUploadsInput{Bucket: "examplebucket"}
+
+	var uploads []string
+	for upload, err := range c.MultipartUploads(context.Background(), input) {
+		require.NoError(t, err)
+		uploads = append(uploads, upload.Key+"/"+upload.UploadID)
+	}
+
+	assert.Equal(t, []string{"a/1", "a/2", "b/3"}, uploads)
+	assert.Len(t, httpClient.requests, 2)
+	assert.Empty(t, input.KeyMarker, "input must not be modified")
+}
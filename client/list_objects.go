@@ -0,0 +1,277 @@
+package client
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"iter"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/lvjp/s3hobby/pkg/s3/api"
+
+	"github.com/valyala/fasthttp"
+)
+
+// EncodingType asks S3 to encode the keys of a listing, which is required
+// for keys holding characters that XML 1.0 does not allow.
+type EncodingType string
+
+// EncodingTypeURL keys are URL encoded by S3 and transparently decoded.
+const EncodingTypeURL EncodingType = "url"
+
+type Object struct {
+	Key               string
+	LastModified      time.Time
+	ETag              string
+	Size              int64
+	StorageClass      string
+	ChecksumAlgorithm []string
+
+	// Owner is only set when requested.
+	Owner *Owner
+}
+
+type Owner struct {
+	ID          string
+	DisplayName string
+}
+
+type ListObjectsInput struct {
+	Bucket string
+
+	Prefix    string
+	Delimiter string
+	Marker    string
+
+	// MaxKeys defaults to 1000 on the server side, which is also its maximum.
+	MaxKeys int
+
+	EncodingType EncodingType
+}
+
+// ListObjectsOutput holds one page of results, keys and prefixes are already
+// decoded when EncodingTypeURL was requested.
+type ListObjectsOutput struct {
+	Name      string
+	Prefix    string
+	Delimiter string
+	MaxKeys   int
+
+	IsTruncated bool
+	Marker      string
+
+	// NextMarker is only returned by S3 along a delimiter, see NextPageMarker.
+	NextMarker string
+
+	Contents       []Object
+	CommonPrefixes []string
+}
+
+// NextPageMarker returns the marker of the next page. S3 omits NextMarker when
+// no delimiter is used, the last key or common prefix of the page must then
+// be used instead.
+func (o *ListObjectsOutput) NextPageMarker() string {
+	if o.NextMarker != "" {
+		return o.NextMarker
+	}
+
+	var marker string
+	if len(o.Contents) > 0 {
+		marker = o.Contents[len(o.Contents)-1].Key
+	}
+
+	if len(o.CommonPrefixes) > 0 {
+		marker = max(marker, o.CommonPrefixes[len(o.CommonPrefixes)-1])
+	}
+
+	return marker
+}
+
+// ListObjects lists one page of the objects of a bucket with the legacy
+// version of the API, prefer ListObjectsV2 when the server supports it.
+// See Objects and CommonPrefixes to iterate over a whole listing.
+func (c *Client) ListObjects(ctx context.Context, input *ListObjectsInput) (*ListObjectsOutput, error) {
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(resp)
+
+	if err := c.setRequestURI(req, input.Bucket, ""); err != nil {
+		return nil, err
+	}
+
+	req.Header.SetMethod(fasthttp.MethodGet)
+
+	args := req.URI().QueryArgs()
+	addQueryArg(args, "prefix", input.Prefix)
+	addQueryArg(args, "delimiter", input.Delimiter)
+	addQueryArg(args, "marker", input.Marker)
+	addQueryArg(args, "encoding-type", string(input.EncodingType))
+
+	if input.MaxKeys > 0 {
+		args.Add("max-keys", strconv.Itoa(input.MaxKeys))
+	}
+
+	if err := c.doBucket(ctx, input.Bucket, req, resp); err != nil {
+		return nil, err
+	}
+
+	var result api.ListObjectsResult
+	if err := xml.Unmarshal(resp.Body(), &result); err != nil {
+		return nil, fmt.Errorf("ListObjects: cannot parse response: %w", err)
+	}
+
+	decoder := keyDecoder{encodingType: result.EncodingType}
+
+	output := &ListObjectsOutput{
+		Name:           result.Name,
+		Prefix:         decoder.decode(result.Prefix),
+		Delimiter:      decoder.decode(result.Delimiter),
+		MaxKeys:        result.MaxKeys,
+		IsTruncated:    result.IsTruncated,
+		Marker:         decoder.decode(result.Marker),
+		NextMarker:     decoder.decode(result.NextMarker),
+		Contents:       decoder.objects(result.Contents),
+		CommonPrefixes: decoder.commonPrefixes(result.CommonPrefixes),
+	}
+
+	if err := decoder.err; err != nil {
+		return nil, fmt.Errorf("ListObjects: %w", err)
+	}
+
+	return output, nil
+}
+
+// ListObjectsPages iterates over the pages of a listing by following the
+// markers. The input is not modified.
+func (c *Client) ListObjectsPages(ctx context.Context, input *ListObjectsInput) iter.Seq2[*ListObjectsOutput, error] {
+	return func(yield func(*ListObjectsOutput, error) bool) {
+		pageInput := *input
+
+		for {
+			output, err := c.ListObjects(ctx, &pageInput)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			if !yield(output, nil) || !output.IsTruncated {
+				return
+			}
+
+			marker := output.NextPageMarker()
+			if marker == "" || marker == pageInput.Marker {
+				yield(nil, errors.New("ListObjects: truncated listing without next marker"))
+				return
+			}
+
+			pageInput.Marker = marker
+		}
+	}
+}
+
+// Objects iterates over every object of a legacy listing, the iteration stops
+// after the first error.
+func (c *Client) Objects(ctx context.Context, input *ListObjectsInput) iter.Seq2[Object, error] {
+	return flattenPages(c.ListObjectsPages(ctx, input), func(output *ListObjectsOutput) []Object {
+		return output.Contents
+	})
+}
+
+// CommonPrefixes iterates over every common prefix of a legacy listing, which
+// requires a delimiter. The iteration stops after the first error.
+func (c *Client) CommonPrefixes(ctx context.Context, input *ListObjectsInput) iter.Seq2[string, error] {
+	return flattenPages(c.ListObjectsPages(ctx, input), func(output *ListObjectsOutput) []string {
+		return output.CommonPrefixes
+	})
+}
+
+// flattenPages iterates over the items of every page, the iteration stops
+// after the first error.
+func flattenPages[P, T any](pages iter.Seq2[P, error], items func(P) []T) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for page, err := range pages {
+			if err != nil {
+				var zero T
+				yield(zero, err)
+
+				return
+			}
+
+			for _, item := range items(page) {
+				if !yield(item, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func addQueryArg(args *fasthttp.Args, key, value string) {
+	if value != "" {
+		args.Add(key, value)
+	}
+}
+
+// keyDecoder decodes the keys of a listing according to its encoding type.
+// The first decoding error is kept in err.
+type keyDecoder struct {
+	encodingType string
+	err          error
+}
+
+func (d *keyDecoder) decode(v string) string {
+	if d.encodingType != string(EncodingTypeURL) || d.err != nil {
+		return v
+	}
+
+	decoded, err := url.QueryUnescape(v)
+	if err != nil {
+		d.err = fmt.Errorf("cannot decode key %q: %w", v, err)
+		return v
+	}
+
+	return decoded
+}
+
+func (d *keyDecoder) objects(objects []api.Object) []Object {
+	ret := make([]Object, 0, len(objects))
+
+	for _, object := range objects {
+		ret = append(ret, Object{
+			Key:               d.decode(object.Key),
+			LastModified:      object.LastModified,
+			ETag:              object.ETag,
+			Size:              object.Size,
+			StorageClass:      object.StorageClass,
+			ChecksumAlgorithm: object.ChecksumAlgorithm,
+			Owner:             ownerFromAPI(object.Owner),
+		})
+	}
+
+	return ret
+}
+
+func (d *keyDecoder) commonPrefixes(prefixes []api.CommonPrefix) []string {
+	ret := make([]string, 0, len(prefixes))
+
+	for _, prefix := range prefixes {
+		ret = append(ret, d.decode(prefix.Prefix))
+	}
+
+	return ret
+}
+
+func ownerFromAPI(owner *api.Owner) *Owner {
+	if owner == nil {
+		return nil
+	}
+
+	return &Owner{
+		ID:          owner.ID,
+		DisplayName: owner.DisplayName,
+	}
+}
@@ -0,0 +1,166 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func TestListObjects(t *testing.T) {
+	c, httpClient, _ := newTestClient(t, func(*fasthttp.Request) string {
+		return rawResponse(fasthttp.StatusOK, `<?xml version="1.0" encoding="UTF-8"?>
+<ListBucketResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+  <Name>examplebucket</Name>
+  <Prefix>photos%2F</Prefix>
+  <Marker>photos%2Fa</Marker>
+  <NextMarker>photos%2Fmy+photo.jpg</NextMarker>
+  <Delimiter>%2F</Delimiter>
+  <EncodingType>url</EncodingType>
+  <MaxKeys>1</MaxKeys>
+  <IsTruncated>true</IsTruncated>
+  <Contents>
+    <Key>photos%2Fmy+photo.jpg</Key>
+    <Size>434234</Size>
+  </Contents>
+</ListBucketResult>`)
+	})
+
+	output, err := c.ListObjects(context.Background(), &ListObjectsInput{
+		Bucket:       "examplebucket",
+		Prefix:       "photos/",
+		Delimiter:    "/",
+		Marker:       "photos/a",
+		MaxKeys:      1,
+		EncodingType: EncodingTypeURL,
+	})
+	require.NoError(t, err)
+
+	require.Len(t, httpClient.requests, 1)
+	assert.Equal(t, "prefix=photos%2F&delimiter=%2F&marker=photos%2Fa&encoding-type=url&max-keys=1", httpClient.requests[0].URI().QueryArgs().String())
+
+	assert.Equal(t, &ListObjectsOutput{
+		Name:           "examplebucket",
+		Prefix:         "photos/",
+		Delimiter:      "/",
+		MaxKeys:        1,
+		IsTruncated:    true,
+		Marker:         "photos/a",
+		NextMarker:     "photos/my photo.jpg",
+		Contents:       []Object{{Key: "photos/my photo.jpg", Size: 434234}},
+		CommonPrefixes: []string{},
+	}, output)
+}
+
+func TestListObjectsOutputNextPageMarker(t *testing.T) {
+	testCases := []struct {
+		name     string
+		output   ListObjectsOutput
+		expected string
+	}{
+		{
+			name:     "next marker",
+			output:   ListObjectsOutput{NextMarker: "b", Contents: []Object{{Key: "a"}}},
+			expected: "b",
+		},
+		{
+			name:     "last key",
+			output:   ListObjectsOutput{Contents: []Object{{Key: "a"}, {Key: "b"}}},
+			expected: "b",
+		},
+		{
+			name:     "last common prefix",
+			output:   ListObjectsOutput{Contents: []Object{{Key: "a"}}, CommonPrefixes: []string{"b/"}},
+			expected: "b/",
+		},
+		{
+			name:     "last key after common prefix",
+			output:   ListObjectsOutput{Contents: []Object{{Key: "c"}}, CommonPrefixes: []string{"b/"}},
+			expected: "c",
+		},
+		{
+			name: "empty page",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.output.NextPageMarker())
+		})
+	}
+}
+
+func TestObjects(t *testing.T) {
+	// Markers are only returned along a delimiter, like S3 does.
+	handler := func(req *fasthttp.Request) string {
+		args := req.URI().QueryArgs()
+
+		var page string
+		switch marker := string(args.Peek("marker")); marker {
+		case "":
+			page = "<IsTruncated>true</IsTruncated><Contents><Key>a</Key></Contents><Contents><Key>b</Key></Contents>"
+		case "b":
+			page = "<IsTruncated>true</IsTruncated><Contents><Key>c</Key></Contents><CommonPrefixes><Prefix>d/</Prefix></CommonPrefixes>"
+		case "d/":
+			page = "<Contents><Key>e</Key></Contents>"
+		default:
+			t.Errorf("unexpected marker %q", marker)
+		}
+
+		if args.Has("delimiter") && page != "" {
+			page += "<NextMarker>" + string(args.Peek("marker")) + "-next</NextMarker>"
+		}
+
+		return rawResponse(fasthttp.StatusOK, "<ListBucketResult>"+page+"</ListBucketResult>")
+	}
+
+	t.Run("without next marker", func(t *testing.T) {
+		c, httpClient, _ := newTestClient(t, handler)
+
+		var keys []string
+		for object, err := range c.Objects(context.Background(), &ListObjectsInput{Bucket: "examplebucket"}) {
+			require.NoError(t, err)
+			keys = append(keys, object.Key)
+		}
+
+		assert.Equal(t, []string{"a", "b", "c", "e"}, keys)
+		assert.Len(t, httpClient.requests, 3)
+	})
+
+	t.Run("with next marker", func(t *testing.T) {
+		c, httpClient, _ := newTestClient(t, func(req *fasthttp.Request) string {
+			if marker := string(req.URI().QueryArgs().Peek("marker")); marker != "" {
+				assert.Equal(t, "-next", marker)
+				return rawResponse(fasthttp.StatusOK, "<ListBucketResult><CommonPrefixes><Prefix>z/</Prefix></CommonPrefixes></ListBucketResult>")
+			}
+
+			return handler(req)
+		})
+
+		var prefixes []string
+		for prefix, err := range c.CommonPrefixes(context.Background(), &ListObjectsInput{Bucket: "examplebucket", Delimiter: "/"}) {
+			require.NoError(t, err)
+			prefixes = append(prefixes, prefix)
+		}
+
+		assert.Equal(t, []string{"z/"}, prefixes)
+		assert.Len(t, httpClient.requests, 2)
+	})
+
+	t.Run("truncated empty page", func(t *testing.T) {
+		c, httpClient, _ := newTestClient(t, func(*fasthttp.Request) string {
+			return rawResponse(fasthttp.StatusOK, "<ListBucketResult><IsTruncated>true</IsTruncated></ListBucketResult>")
+		})
+
+		var errs int
+		for _, err := range c.Objects(context.Background(), &ListObjectsInput{Bucket: "examplebucket"}) {
+			require.Error(t, err)
+			errs++
+		}
+
+		assert.Equal(t, 1, errs)
+		assert.Len(t, httpClient.requests, 1)
+	})
+}
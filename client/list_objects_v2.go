@@ -0,0 +1,152 @@
+package client
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"iter"
+	"strconv"
+
+	"github.com/lvjp/s3hobby/pkg/s3/api"
+
+	"github.com/valyala/fasthttp"
+)
+
+type ListObjectsV2Input struct {
+	Bucket string
+
+	Prefix            string
+	Delimiter         string
+	StartAfter        string
+	ContinuationToken string
+
+	// MaxKeys defaults to 1000 on the server side, which is also its maximum.
+	MaxKeys int
+
+	FetchOwner   bool
+	EncodingType EncodingType
+}
+
+// ListObjectsV2Output holds one page of results, keys and prefixes are
+// already decoded when EncodingTypeURL was requested.
+type ListObjectsV2Output struct {
+	Name       string
+	Prefix     string
+	Delimiter  string
+	StartAfter string
+	MaxKeys    int
+	KeyCount   int
+
+	IsTruncated           bool
+	ContinuationToken     string
+	NextContinuationToken string
+
+	Contents       []Object
+	CommonPrefixes []string
+}
+
+// ListObjectsV2 lists one page of the objects of a bucket, see ObjectsV2 and
+// CommonPrefixesV2 to iterate over a whole listing.
+func (c *Client) ListObjectsV2(ctx context.Context, input *ListObjectsV2Input) (*ListObjectsV2Output, error) {
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(resp)
+
+	if err := c.setRequestURI(req, input.Bucket, ""); err != nil {
+		return nil, err
+	}
+
+	req.Header.SetMethod(fasthttp.MethodGet)
+
+	args := req.URI().QueryArgs()
+	args.Add("list-type", "2")
+	addQueryArg(args, "prefix", input.Prefix)
+	addQueryArg(args, "delimiter", input.Delimiter)
+	addQueryArg(args, "start-after", input.StartAfter)
+	addQueryArg(args, "continuation-token", input.ContinuationToken)
+	addQueryArg(args, "encoding-type", string(input.EncodingType))
+
+	if input.MaxKeys > 0 {
+		args.Add("max-keys", strconv.Itoa(input.MaxKeys))
+	}
+
+	if input.FetchOwner {
+		args.Add("fetch-owner", "true")
+	}
+
+	if err := c.doBucket(ctx, input.Bucket, req, resp); err != nil {
+		return nil, err
+	}
+
+	var result api.ListObjectsV2Result
+	if err := xml.Unmarshal(resp.Body(), &result); err != nil {
+		return nil, fmt.Errorf("ListObjectsV2: cannot parse response: %w", err)
+	}
+
+	decoder := keyDecoder{encodingType: result.EncodingType}
+
+	output := &ListObjectsV2Output{
+		Name:                  result.Name,
+		Prefix:                decoder.decode(result.Prefix),
+		Delimiter:             decoder.decode(result.Delimiter),
+		StartAfter:            decoder.decode(result.StartAfter),
+		MaxKeys:               result.MaxKeys,
+		KeyCount:              result.KeyCount,
+		IsTruncated:           result.IsTruncated,
+		ContinuationToken:     result.ContinuationToken,
+		NextContinuationToken: result.NextContinuationToken,
+		Contents:              decoder.objects(result.Contents),
+		CommonPrefixes:        decoder.commonPrefixes(result.CommonPrefixes),
+	}
+
+	if err := decoder.err; err != nil {
+		return nil, fmt.Errorf("ListObjectsV2: %w", err)
+	}
+
+	return output, nil
+}
+
+// ListObjectsV2Pages iterates over the pages of a listing by following the
+// continuation tokens. The input is not modified.
+func (c *Client) ListObjectsV2Pages(ctx context.Context, input *ListObjectsV2Input) iter.Seq2[*ListObjectsV2Output, error] {
+	return func(yield func(*ListObjectsV2Output, error) bool) {
+		pageInput := *input
+
+		for {
+			output, err := c.ListObjectsV2(ctx, &pageInput)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			if !yield(output, nil) || !output.IsTruncated {
+				return
+			}
+
+			if output.NextContinuationToken == "" {
+				yield(nil, errors.New("ListObjectsV2: truncated listing without continuation token"))
+				return
+			}
+
+			pageInput.ContinuationToken = output.NextContinuationToken
+		}
+	}
+}
+
+// ObjectsV2 iterates over every object of a listing, the iteration stops
+// after the first error.
+func (c *Client) ObjectsV2(ctx context.Context, input *ListObjectsV2Input) iter.Seq2[Object, error] {
+	return flattenPages(c.ListObjectsV2Pages(ctx, input), func(output *ListObjectsV2Output) []Object {
+		return output.Contents
+	})
+}
+
+// CommonPrefixesV2 iterates over every common prefix of a listing, which
+// requires a delimiter. The iteration stops after the first error.
+func (c *Client) CommonPrefixesV2(ctx context.Context, input *ListObjectsV2Input) iter.Seq2[string, error] {
+	return flattenPages(c.ListObjectsV2Pages(ctx, input), func(output *ListObjectsV2Output) []string {
+		return output.CommonPrefixes
+	})
+}
@@ -0,0 +1,175 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func TestListObjectsV2(t *testing.T) {
+	c, httpClient, _ := newTestClient(t, func(*fasthttp.Request) string {
+		return rawResponse(fasthttp.StatusOK, `<?xml version="1.0" encoding="UTF-8"?>
+<ListBucketResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+  <Name>examplebucket</Name>
+  <Prefix>photos%2F</Prefix>
+  <Delimiter>%2F</Delimiter>
+  <EncodingType>url</EncodingType>
+  <KeyCount>2</KeyCount>
+  <MaxKeys>2</MaxKeys>
+  <IsTruncated>true</IsTruncated>
+  <NextContinuationToken>1ueGcxLPRx1Tr/XYExHnhbYLgveDs2J/wm36Hy4vbOwM=</NextContinuationToken>
+  <Contents>
+    <Key>photos%2Fmy+photo%E2%82%AC.jpg</Key>
+    <LastModified>2009-10-12T17:50:30.000Z</LastModified>
+    <ETag>&quot;fba9dede5f27731c9771645a39863328&quot;</ETag>
+    <Size>434234</Size>
+    <StorageClass>STANDARD</StorageClass>
+    <ChecksumAlgorithm>CRC32</ChecksumAlgorithm>
+    <Owner>
+      <ID>75aa57f09aa0c8caeab4f8c24e99d10f8e7faeebf76c078efc7c6caea54ba06a</ID>
+      <DisplayName>mtd@amazon.com</DisplayName>
+    </Owner>
+  </Contents>
+  <CommonPrefixes>
+    <Prefix>photos%2F2006%2F</Prefix>
+  </CommonPrefixes>
+</ListBucketResult>`)
+	})
+
+	output, err := c.ListObjectsV2(context.Background(), &ListObjectsV2Input{
+		Bucket:       "examplebucket",
+		Prefix:       "photos/",
+		Delimiter:    "/",
+		StartAfter:   "photos/a",
+		MaxKeys:      2,
+		FetchOwner:   true,
+		EncodingType: EncodingTypeURL,
+	})
+	require.NoError(t, err)
+
+	require.Len(t, httpClient.requests, 1)
+	sent := httpClient.requests[0]
+	assert.Equal(t, fasthttp.MethodGet, string(sent.Header.Method()))
+	assert.Equal(t, "examplebucket.s3.us-east-1.amazonaws.com", string(sent.Host()))
+	assert.Equal(t, "list-type=2&prefix=photos%2F&delimiter=%2F&start-after=photos%2Fa&encoding-type=url&max-keys=2&fetch-owner=true", sent.URI().QueryArgs().String())
+
+	assert.Equal(t, &ListObjectsV2Output{
+		Name:                  "examplebucket",
+		Prefix:                "photos/",
+		Delimiter:             "/",
+		MaxKeys:               2,
+		KeyCount:              2,
+		IsTruncated:           true,
+		NextContinuationToken: "1ueGcxLPRx1Tr/XYExHnhbYLgveDs2J/wm36Hy4vbOwM=",
+		Contents: []Object{
+			{
+				Key:               "photos/my photo€.jpg",
+				LastModified:      time.Date(2009, time.October, 12, 17, 50, 30, 0, time.UTC),
+				ETag:              `"fba9dede5f27731c9771645a39863328"`,
+				Size:              434234,
+				StorageClass:      "STANDARD",
+				ChecksumAlgorithm: []string{"CRC32"},
+				Owner: &Owner{
+					ID:          "75aa57f09aa0c8caeab4f8c24e99d10f8e7faeebf76c078efc7c6caea54ba06a",
+					DisplayName: "mtd@amazon.com",
+				},
+			},
+		},
+		CommonPrefixes: []string{"photos/2006/"},
+	}, output)
+}
+
+// listPages answers a listing request with the page matching its
+// continuation token, pages are chained by their index.
+func listPages(pages ...string) func(req *fasthttp.Request) string {
+	return func(req *fasthttp.Request) string {
+		token := string(req.URI().QueryArgs().Peek("continuation-token"))
+
+		index := 0
+		if token != "" {
+			index = int(token[0] - '0')
+		}
+
+		body := "<ListBucketResult>" + pages[index]
+		if index+1 < len(pages) {
+			body += "<IsTruncated>true</IsTruncated><NextContinuationToken>" + string(rune('0'+index+1)) + "</NextContinuationToken>"
+		}
+
+		return rawResponse(fasthttp.StatusOK, body+"</ListBucketResult>")
+	}
+}
+
+func TestObjectsV2(t *testing.T) {
+	pages := listPages(
+		"<Contents><Key>a</Key></Contents><Contents><Key>b</Key></Contents><CommonPrefixes><Prefix>p1/</Prefix></CommonPrefixes>",
+		"<CommonPrefixes><Prefix>p2/</Prefix></CommonPrefixes>",
+		"<Contents><Key>c</Key></Contents>",
+	)
+
+	input := &ListObjectsV2Input{Bucket: "examplebucket", Delimiter: "/"}
+
+	t.Run("objects", func(t *testing.T) {
+		c, httpClient, _ := newTestClient(t, pages)
+
+		var keys []string
+		for object, err := range c.ObjectsV2(context.Background(), input) {
+			require.NoError(t, err)
+			keys = append(keys, object.Key)
+		}
+
+		assert.Equal(t, []string{"a", "b", "c"}, keys)
+		assert.Len(t, httpClient.requests, 3)
+		assert.Empty(t, input.ContinuationToken, "input must not be modified")
+	})
+
+	t.Run("common prefixes", func(t *testing.T) {
+		c, _, _ := newTestClient(t, pages)
+
+		var prefixes []string
+		for prefix, err := range c.CommonPrefixesV2(context.Background(), input) {
+			require.NoError(t, err)
+			prefixes = append(prefixes, prefix)
+		}
+
+		assert.Equal(t, []string{"p1/", "p2/"}, prefixes)
+	})
+
+	t.Run("early break", func(t *testing.T) {
+		c, httpClient, _ := newTestClient(t, pages)
+
+		for range c.ObjectsV2(context.Background(), input) {
+			break
+		}
+
+		assert.Len(t, httpClient.requests, 1)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		c, _, _ := newTestClient(t, func(req *fasthttp.Request) string {
+			if req.URI().QueryArgs().Has("continuation-token") {
+				return rawResponse(fasthttp.StatusForbidden, "<Error><Code>AccessDenied</Code></Error>")
+			}
+
+			return pages(req)
+		})
+
+		var keys []string
+		var errs []error
+		for object, err := range c.ObjectsV2(context.Background(), input) {
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+
+			keys = append(keys, object.Key)
+		}
+
+		assert.Equal(t, []string{"a", "b"}, keys)
+		require.Len(t, errs, 1)
+		assert.EqualError(t, errs[0], "s3: 403 AccessDenied")
+	})
+}
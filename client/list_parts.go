@@ -0,0 +1,155 @@
+package client
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"iter"
+	"strconv"
+	"time"
+
+	"github.com/lvjp/s3hobby/pkg/s3/api"
+
+	"github.com/valyala/fasthttp"
+)
+
+type Part struct {
+	PartNumber   int
+	LastModified time.Time
+	ETag         string
+	Size         int64
+	Checksums    Checksums
+}
+
+// CompletedPart returns the part as expected by CompleteMultipartUpload, which
+// is handy to resume an upload.
+func (p *Part) CompletedPart() CompletedPart {
+	return CompletedPart{
+		PartNumber: p.PartNumber,
+		ETag:       p.ETag,
+		Checksums:  p.Checksums,
+	}
+}
+
+type ListPartsInput struct {
+	Bucket   string
+	Key      string
+	UploadID string
+
+	PartNumberMarker int
+
+	// MaxParts defaults to 1000 on the server side, which is also its maximum.
+	MaxParts int
+}
+
+type ListPartsOutput struct {
+	Bucket   string
+	Key      string
+	UploadID string
+
+	IsTruncated          bool
+	PartNumberMarker     int
+	NextPartNumberMarker int
+
+	StorageClass      string
+	ChecksumAlgorithm ChecksumAlgorithm
+	ChecksumType      ChecksumType
+
+	Parts []Part
+}
+
+// ListParts lists one page of the parts of a multipart upload, see Parts to
+// iterate over every part.
+func (c *Client) ListParts(ctx context.Context, input *ListPartsInput) (*ListPartsOutput, error) {
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(resp)
+
+	if err := c.setRequestURI(req, input.Bucket, input.Key); err != nil {
+		return nil, err
+	}
+
+	req.Header.SetMethod(fasthttp.MethodGet)
+
+	args := req.URI().QueryArgs()
+	args.Add("uploadId", input.UploadID)
+
+	if input.PartNumberMarker > 0 {
+		args.Add("part-number-marker", strconv.Itoa(input.PartNumberMarker))
+	}
+
+	if input.MaxParts > 0 {
+		args.Add("max-parts", strconv.Itoa(input.MaxParts))
+	}
+
+	if err := c.doBucket(ctx, input.Bucket, req, resp); err != nil {
+		return nil, err
+	}
+
+	var result api.ListPartsResult
+	if err := xml.Unmarshal(resp.Body(), &result); err != nil {
+		return nil, fmt.Errorf("ListParts: cannot parse response: %w", err)
+	}
+
+	output := &ListPartsOutput{
+		Bucket:               result.Bucket,
+		Key:                  result.Key,
+		UploadID:             result.UploadID,
+		IsTruncated:          result.IsTruncated,
+		PartNumberMarker:     result.PartNumberMarker,
+		NextPartNumberMarker: result.NextPartNumberMarker,
+		StorageClass:         result.StorageClass,
+		ChecksumAlgorithm:    ChecksumAlgorithm(result.ChecksumAlgorithm),
+		ChecksumType:         ChecksumType(result.ChecksumType),
+		Parts:                make([]Part, 0, len(result.Parts)),
+	}
+
+	for _, part := range result.Parts {
+		output.Parts = append(output.Parts, Part{
+			PartNumber:   part.PartNumber,
+			LastModified: part.LastModified,
+			ETag:         part.ETag,
+			Size:         part.Size,
+			Checksums:    checksumsFromAPI(part.Checksums),
+		})
+	}
+
+	return output, nil
+}
+
+// ListPartsPages iterates over the pages of parts by following the part
+// number markers. The input is not modified.
+func (c *Client) ListPartsPages(ctx context.Context, input *ListPartsInput) iter.Seq2[*ListPartsOutput, error] {
+	return func(yield func(*ListPartsOutput, error) bool) {
+		pageInput := *input
+
+		for {
+			output, err := c.ListParts(ctx, &pageInput)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			if !yield(output, nil) || !output.IsTruncated {
+				return
+			}
+
+			if output.NextPartNumberMarker <= pageInput.PartNumberMarker {
+				yield(nil, errors.New("ListParts: truncated listing without next part number marker"))
+				return
+			}
+
+			pageInput.PartNumberMarker = output.NextPartNumberMarker
+		}
+	}
+}
+
+// Parts iterates over every part of a multipart upload, the iteration stops
+// after the first error.
+func (c *Client) Parts(ctx context.Context, input *ListPartsInput) iter.Seq2[Part, error] {
+	return flattenPages(c.ListPartsPages(ctx, input), func(output *ListPartsOutput) []Part {
+		return output.Parts
+	})
+}
@@ -0,0 +1,137 @@
+package client
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func TestListParts(t *testing.T) {
+	c, httpClient, _ := newTestClient(t, func(*fasthttp.Request) string {
+		return rawResponse(fasthttp.StatusOK, `<?xml version="1.0" encoding="UTF-8"?>
+<ListPartsResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+  <Bucket>examplebucket</Bucket>
+  <Key>example-object</Key>
+  <UploadId>UPLOAD</UploadId>
+  <StorageClass>STANDARD</StorageClass>
+  <PartNumberMarker>1</PartNumberMarker>
+  <NextPartNumberMarker>3</NextPartNumberMarker>
+  <MaxParts>2</MaxParts>
+  <IsTruncated>true</IsTruncated>
+  <ChecksumAlgorithm>CRC32</ChecksumAlgorithm>
+  <ChecksumType>COMPOSITE</ChecksumType>
+  <Part>
+    <PartNumber>2</PartNumber>
+    <LastModified>2010-11-10T20:48:34.000Z</LastModified>
+    <ETag>"7778aef83f66abc1fa1e8477f296d394"</ETag>
+    <Size>10485760</Size>
+    <ChecksumCRC32>part2</ChecksumCRC32>
+  </Part>
+  <Part>
+    <PartNumber>3</PartNumber>
+    <LastModified>2010-11-10T20:48:33.000Z</LastModified>
+    <ETag>"aaaa18db4cc2f85cedef654fccc4a4x8"</ETag>
+    <Size>10485760</Size>
+    <ChecksumCRC32>part3</ChecksumCRC32>
+  </Part>
+</ListPartsResult>`)
+	})
+
+	output, err := c.ListParts(context.Background(), &ListPartsInput{
+		Bucket:           "examplebucket",
+		Key:              "example-object",
+		UploadID:         "UPLOAD",
+		PartNumberMarker: 1,
+		MaxParts:         2,
+	})
+	require.NoError(t, err)
+
+	require.Len(t, httpClient.requests, 1)
+	sent := httpClient.requests[0]
+	assert.Equal(t, fasthttp.MethodGet, string(sent.Header.Method()))
+	assert.Equal(t, "uploadId=UPLOAD&part-number-marker=1&max-parts=2", sent.URI().QueryArgs().String())
+
+	assert.Equal(t, &ListPartsOutput{
+		Bucket:               "examplebucket",
+		Key:                  "example-object",
+		UploadID:             "UPLOAD",
+		IsTruncated:          true,
+		PartNumberMarker:     1,
+		NextPartNumberMarker: 3,
+		StorageClass:         "STANDARD",
+		ChecksumAlgorithm:    ChecksumAlgorithmCRC32,
+		ChecksumType:         ChecksumTypeComposite,
+		Parts: []Part{
+			{
+				PartNumber:   2,
+				LastModified: time.Date(2010, time.November, 10, 20, 48, 34, 0, time.UTC),
+				ETag:         `"7778aef83f66abc1fa1e8477f296d394"`,
+				Size:         10485760,
+				Checksums:    Checksums{CRC32: "part2"},
+			},
+			{
+				PartNumber:   3,
+				LastModified: time.Date(2010, time.November, 10, 20, 48, 33, 0, time.UTC),
+				ETag:         `"aaaa18db4cc2f85cedef654fccc4a4x8"`,
+				Size:         10485760,
+				Checksums:    Checksums{CRC32: "part3"},
+			},
+		},
+	}, output)
+
+	assert.Equal(t, CompletedPart{
+		PartNumber: 2,
+		ETag:       `"7778aef83f66abc1fa1e8477f296d394"`,
+		Checksums:  Checksums{CRC32: "part2"},
+	}, output.Parts[0].CompletedPart())
+}
+
+func TestParts(t *testing.T) {
+	// Every page holds a single part, the last one being the third.
+	pages := func(req *fasthttp.Request) string {
+		marker, _ := strconv.Atoi(string(req.URI().QueryArgs().Peek("part-number-marker")))
+		next := strconv.Itoa(marker + 1)
+
+		body := "<ListPartsResult><Part><PartNumber>" + next + "</PartNumber></Part>"
+		if marker < 2 {
+			body += "<IsTruncated>true</IsTruncated><NextPartNumberMarker>" + next + "</NextPartNumberMarker>"
+		}
+
+		return rawResponse(fasthttp.StatusOK, body+"</ListPartsResult>")
+	}
+
+	input := &ListPartsInput{Bucket: "examplebucket", Key: "example-object", UploadID: "UPLOAD"}
+
+	t.Run("parts", func(t *testing.T) {
+		c, httpClient, _ := newTestClient(t, pages)
+
+		var partNumbers []int
+		for part, err := range c.Parts(context.Background(), input) {
+			require.NoError(t, err)
+			partNumbers = append(partNumbers, part.PartNumber)
+		}
+
+		assert.Equal(t, []int{1, 2, 3}, partNumbers)
+		assert.Len(t, httpClient.requests, 3)
+		assert.Zero(t, input.PartNumberMarker, "input must not be modified")
+	})
+
+	t.Run("missing marker", func(t *testing.T) {
+		c, _, _ := newTestClient(t, func(*fasthttp.Request) string {
+			return rawResponse(fasthttp.StatusOK, "<ListPartsResult><IsTruncated>true</IsTruncated></ListPartsResult>")
+		})
+
+		var errs []error
+		for _, err := range c.Parts(context.Background(), input) {
+			errs = append(errs, err)
+		}
+
+		require.Len(t, errs, 1)
+		assert.EqualError(t, errs[0], "ListParts: truncated listing without next part number marker")
+	})
+}
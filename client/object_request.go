@@ -0,0 +1,177 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/lvjp/s3hobby/pkg/s3/api"
+
+	"github.com/valyala/fasthttp"
+)
+
+type byteRangeKind int
+
+const (
+	byteRangeNone byteRangeKind = iota
+	byteRangeBounded
+	byteRangeOpen
+	byteRangeSuffix
+)
+
+// ByteRange is the HTTP range of the bytes read by GetObject and HeadObject.
+// The zero value reads the whole object.
+type ByteRange struct {
+	kind  byteRangeKind
+	first int64
+	last  int64
+}
+
+// NewByteRange returns the range from first to last, both included.
+func NewByteRange(first, last int64) ByteRange {
+	return ByteRange{kind: byteRangeBounded, first: first, last: last}
+}
+
+// ByteRangeFrom returns the range from first to the end of the object.
+func ByteRangeFrom(first int64) ByteRange {
+	return ByteRange{kind: byteRangeOpen, first: first}
+}
+
+// ByteRangeSuffix returns the range of the length last bytes of the object.
+func ByteRangeSuffix(length int64) ByteRange {
+	return ByteRange{kind: byteRangeSuffix, last: length}
+}
+
+// IsZero reports whether the range reads the whole object.
+func (r ByteRange) IsZero() bool {
+	return r.kind == byteRangeNone
+}
+
+// String returns the value of the Range header, empty for the zero value.
+func (r ByteRange) String() string {
+	switch r.kind {
+	case byteRangeBounded:
+		return fmt.Sprintf("bytes=%d-%d", r.first, r.last)
+	case byteRangeOpen:
+		return fmt.Sprintf("bytes=%d-", r.first)
+	case byteRangeSuffix:
+		return fmt.Sprintf("bytes=-%d", r.last)
+	default:
+		return ""
+	}
+}
+
+func (r ByteRange) validate() error {
+	switch {
+	case r.kind == byteRangeBounded && (r.first < 0 || r.last < r.first):
+		return fmt.Errorf("invalid range from %d to %d", r.first, r.last)
+	case r.kind == byteRangeOpen && r.first < 0:
+		return fmt.Errorf("invalid range from %d", r.first)
+	case r.kind == byteRangeSuffix && r.last < 1:
+		return fmt.Errorf("invalid range of the last %d bytes", r.last)
+	default:
+		return nil
+	}
+}
+
+// ResponseHeaders override the headers of the response, they are sent as
+// response-* query parameters. Anonymous requests cannot use them.
+type ResponseHeaders struct {
+	CacheControl       string
+	ContentDisposition string
+	ContentEncoding    string
+	ContentLanguage    string
+	ContentType        string
+	Expires            time.Time
+}
+
+func (h *ResponseHeaders) addQueryArgs(args *fasthttp.Args) {
+	addQueryArg(args, "response-cache-control", h.CacheControl)
+	addQueryArg(args, "response-content-disposition", h.ContentDisposition)
+	addQueryArg(args, "response-content-encoding", h.ContentEncoding)
+	addQueryArg(args, "response-content-language", h.ContentLanguage)
+	addQueryArg(args, "response-content-type", h.ContentType)
+
+	if !h.Expires.IsZero() {
+		args.AddBytesV("response-expires", fasthttp.AppendHTTPDate(nil, h.Expires))
+	}
+}
+
+// objectRequest holds the parameters shared by GetObject and HeadObject.
+type objectRequest struct {
+	bucket    string
+	key       string
+	versionID string
+
+	partNumber int
+	byteRange  ByteRange
+
+	ifMatch           string
+	ifNoneMatch       string
+	ifModifiedSince   time.Time
+	ifUnmodifiedSince time.Time
+
+	responseHeaders ResponseHeaders
+	checksumMode    bool
+	sseCustomerKey  []byte
+}
+
+// setObjectRequest prepares the requests reading an object.
+func (c *Client) setObjectRequest(req *fasthttp.Request, method string, params *objectRequest) error {
+	if err := params.byteRange.validate(); err != nil {
+		return err
+	}
+
+	if params.partNumber != 0 {
+		if params.partNumber < 1 || params.partNumber > MaxPartNumber {
+			return errors.New("part number must be between 1 and 10000")
+		}
+
+		if !params.byteRange.IsZero() {
+			return errors.New("part number and range are mutually exclusive")
+		}
+	}
+
+	if err := c.setRequestURI(req, params.bucket, params.key); err != nil {
+		return err
+	}
+
+	req.Header.SetMethod(method)
+
+	args := req.URI().QueryArgs()
+	addQueryArg(args, "versionId", params.versionID)
+
+	if params.partNumber != 0 {
+		args.Add("partNumber", strconv.Itoa(params.partNumber))
+	}
+
+	params.responseHeaders.addQueryArgs(args)
+
+	setHeader(&req.Header, api.HeaderRange, params.byteRange.String())
+	setHeader(&req.Header, api.HeaderIfMatch, params.ifMatch)
+	setHeader(&req.Header, api.HeaderIfNoneMatch, params.ifNoneMatch)
+	setDateHeader(&req.Header, api.HeaderIfModifiedSince, params.ifModifiedSince)
+	setDateHeader(&req.Header, api.HeaderIfUnmodifiedSince, params.ifUnmodifiedSince)
+	setSSECustomerKey(&req.Header, params.sseCustomerKey)
+
+	if params.checksumMode {
+		req.Header.Set(api.HeaderXAmzChecksumMode, "ENABLED")
+	}
+
+	return nil
+}
+
+// doObjectRequest sends a request prepared by setObjectRequest. It reports
+// whether the response is a 304 Not Modified, which is not an error.
+func (c *Client) doObjectRequest(ctx context.Context, bucket string, req *fasthttp.Request, resp *fasthttp.Response) (bool, error) {
+	err := c.doBucket(ctx, bucket, req, resp)
+
+	var apiErr *api.Error
+	if errors.As(err, &apiErr) && apiErr.StatusCode == fasthttp.StatusNotModified {
+		return true, nil
+	}
+
+	return false, conditionalError(err)
+}
@@ -0,0 +1,160 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lvjp/s3hobby/pkg/s3/api"
+	"github.com/lvjp/s3hobby/pkg/s3/signing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func TestGetObjectTagging(t *testing.T) {
+	c, httpClient, _ := newTestClient(t, func(*fasthttp.Request) string {
+		return rawResponse(fasthttp.StatusOK, `<?xml version="1.0" encoding="UTF-8"?>
+<Tagging xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+  <TagSet>
+    <Tag>
+      <Key>tag1</Key>
+      <Value>val1</Value>
+    </Tag>
+    <Tag>
+      <Key>tag2</Key>
+      <Value>val2</Value>
+    </Tag>
+  </TagSet>
+</Tagging>`, "x-amz-version-id: ydlaNkwWm0SfKJR.T1b1fIdPRbldTYRI")
+	})
+
+	output, err := c.GetObjectTagging(context.Background(), &GetObjectTaggingInput{
+		Bucket:    "examplebucket",
+		Key:       "example-object",
+		VersionID: "ydlaNkwWm0SfKJR.T1b1fIdPRbldTYRI",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, &GetObjectTaggingOutput{
+		VersionID: "ydlaNkwWm0SfKJR.T1b1fIdPRbldTYRI",
+		TagSet:    TagSet{{Key: "tag1", Value: "val1"}, {Key: "tag2", Value: "val2"}},
+	}, output)
+
+	require.Len(t, httpClient.requests, 1)
+	sent := httpClient.requests[0]
+	assert.Equal(t, fasthttp.MethodGet, string(sent.Header.Method()))
+	assert.Equal(t, "https://examplebucket.s3.us-east-1.amazonaws.com/example-object?tagging&versionId=ydlaNkwWm0SfKJR.T1b1fIdPRbldTYRI", sent.URI().String())
+}
+
+func TestPutObjectTagging(t *testing.T) {
+	t.Run("tags", func(t *testing.T) {
+		c, httpClient, _ := newTestClient(t, func(*fasthttp.Request) string {
+			return rawResponse(fasthttp.StatusOK, "", "x-amz-version-id: VERSION")
+		})
+
+		output, err := c.PutObjectTagging(context.Background(), &PutObjectTaggingInput{
+			Bucket: "examplebucket",
+			Key:    "example-object",
+			TagSet: TagSet{{Key: "Key3", Value: "Value3"}, {Key: "Key4", Value: "Value4"}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, &PutObjectTaggingOutput{VersionID: "VERSION"}, output)
+
+		require.Len(t, httpClient.requests, 1)
+		sent := httpClient.requests[0]
+		assert.Equal(t, fasthttp.MethodPut, string(sent.Header.Method()))
+		assert.Equal(t, "https://examplebucket.s3.us-east-1.amazonaws.com/example-object?tagging", sent.URI().String())
+
+		body := `<Tagging xmlns="http://s3.amazonaws.com/doc/2006-03-01/"><TagSet>` +
+			`<Tag><Key>Key3</Key><Value>Value3</Value></Tag>` +
+			`<Tag><Key>Key4</Key><Value>Value4</Value></Tag>` +
+			`</TagSet></Tagging>`
+		assert.Equal(t, body, string(sent.Body()))
+		assert.Equal(t, signing.ContentMD5([]byte(body)), string(sent.Header.Peek(api.HeaderContentMD5)))
+	})
+
+	t.Run("invalid tags", func(t *testing.T) {
+		c, httpClient, _ := newTestClient(t, nil)
+
+		_, err := c.PutObjectTagging(context.Background(), &PutObjectTaggingInput{
+			Bucket: "examplebucket",
+			Key:    "example-object",
+			TagSet: TagSet{{Key: "aws:createdBy"}},
+		})
+		require.ErrorIs(t, err, ErrInvalidTagSet)
+		require.EqualError(t, err, `PutObjectTagging: client: invalid tag set: key "aws:createdBy" uses the reserved aws: prefix`)
+		assert.Empty(t, httpClient.requests)
+	})
+}
+
+func TestDeleteObjectTagging(t *testing.T) {
+	c, httpClient, _ := newTestClient(t, func(*fasthttp.Request) string {
+		return rawResponse(fasthttp.StatusNoContent, "", "x-amz-version-id: VERSION")
+	})
+
+	output, err := c.DeleteObjectTagging(context.Background(), &DeleteObjectTaggingInput{
+		Bucket: "examplebucket",
+		Key:    "example-object",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &DeleteObjectTaggingOutput{VersionID: "VERSION"}, output)
+
+	require.Len(t, httpClient.requests, 1)
+	sent := httpClient.requests[0]
+	assert.Equal(t, fasthttp.MethodDelete, string(sent.Header.Method()))
+	assert.Equal(t, "https://examplebucket.s3.us-east-1.amazonaws.com/example-object?tagging", sent.URI().String())
+}
+
+func TestTaggingHeader(t *testing.T) {
+	tagSet := TagSet{{Key: "project", Value: "hobby s3"}, {Key: "team", Value: "a/b"}}
+	const header = "project=hobby+s3&team=a%2Fb"
+
+	t.Run("PutObject", func(t *testing.T) {
+		c, httpClient, _ := newTestClient(t, func(*fasthttp.Request) string {
+			return rawResponse(fasthttp.StatusOK, "")
+		})
+
+		_, err := c.PutObject(context.Background(), &PutObjectInput{Bucket: "examplebucket", Key: "example-object", Tagging: tagSet})
+		require.NoError(t, err)
+		assert.Equal(t, header, string(httpClient.requests[0].Header.Peek(api.HeaderXAmzTagging)))
+
+		_, err = c.PutObject(context.Background(), &PutObjectInput{Bucket: "examplebucket", Key: "example-object", Tagging: TagSet{{Key: "a&b"}}})
+		require.ErrorIs(t, err, ErrInvalidTagSet)
+		assert.Len(t, httpClient.requests, 1)
+	})
+
+	t.Run("CreateMultipartUpload", func(t *testing.T) {
+		c, httpClient, _ := newTestClient(t, func(*fasthttp.Request) string {
+			return rawResponse(fasthttp.StatusOK, "<InitiateMultipartUploadResult><UploadId>UPLOAD</UploadId></InitiateMultipartUploadResult>")
+		})
+
+		_, err := c.CreateMultipartUpload(context.Background(), &CreateMultipartUploadInput{Bucket: "examplebucket", Key: "example-object", Tagging: tagSet})
+		require.NoError(t, err)
+		assert.Equal(t, header, string(httpClient.requests[0].Header.Peek(api.HeaderXAmzTagging)))
+	})
+
+	t.Run("CopyObject", func(t *testing.T) {
+		c, httpClient, _ := newTestClient(t, func(*fasthttp.Request) string {
+			return rawResponse(fasthttp.StatusOK, "<CopyObjectResult><ETag>&quot;copy&quot;</ETag></CopyObjectResult>")
+		})
+
+		input := &CopyObjectInput{
+			Bucket:  "examplebucket",
+			Key:     "copy",
+			Source:  CopySource{Bucket: "sourcebucket", Key: "example"},
+			Tagging: tagSet,
+		}
+
+		_, err := c.CopyObject(context.Background(), input)
+		require.NoError(t, err)
+		assert.Empty(t, httpClient.requests[0].Header.Peek(api.HeaderXAmzTagging), "the tags are only sent when replaced")
+
+		input.TaggingDirective = TaggingDirectiveReplace
+
+		_, err = c.CopyObject(context.Background(), input)
+		require.NoError(t, err)
+		assert.Equal(t, "REPLACE", string(httpClient.requests[1].Header.Peek(api.HeaderXAmzTaggingDirective)))
+		assert.Equal(t, header, string(httpClient.requests[1].Header.Peek(api.HeaderXAmzTagging)))
+	})
+}
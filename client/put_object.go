@@ -0,0 +1,89 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lvjp/s3hobby/pkg/s3/api"
+
+	"github.com/valyala/fasthttp"
+)
+
+type PutObjectInput struct {
+	Bucket string
+	Key    string
+	Body   []byte
+
+	ContentType  string
+	Metadata     map[string]string
+	StorageClass string
+
+	// Tagging is validated before sending.
+	Tagging TagSet
+
+	// ChecksumAlgorithm computes the matching checksum of Body unless it is
+	// already provided by Checksums.
+	ChecksumAlgorithm ChecksumAlgorithm
+	Checksums         Checksums
+
+	// IfNoneMatch set to "*" only creates the object when it does not exist,
+	// IfMatch only overwrites the object having this ETag. The write fails
+	// with ErrPreconditionFailed otherwise, or ErrConditionalRequestConflict
+	// when racing with a concurrent conditional write.
+	IfMatch     string
+	IfNoneMatch string
+}
+
+type PutObjectOutput struct {
+	ETag      string
+	VersionID string
+
+	Checksums    Checksums
+	ChecksumType ChecksumType
+}
+
+// PutObject uploads an object in a single request, see Uploader for objects
+// larger than a few megabytes.
+func (c *Client) PutObject(ctx context.Context, input *PutObjectInput) (*PutObjectOutput, error) {
+	if err := input.Tagging.Validate(); err != nil {
+		return nil, fmt.Errorf("PutObject: %w", err)
+	}
+
+	checksums := input.Checksums
+	if err := checksums.compute(input.ChecksumAlgorithm, input.Body); err != nil {
+		return nil, err
+	}
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(resp)
+
+	if err := c.setRequestURI(req, input.Bucket, input.Key); err != nil {
+		return nil, err
+	}
+
+	req.Header.SetMethod(fasthttp.MethodPut)
+
+	setHeader(&req.Header, api.HeaderContentType, input.ContentType)
+	setHeader(&req.Header, api.HeaderXAmzStorageClass, input.StorageClass)
+	setHeader(&req.Header, api.HeaderXAmzTagging, input.Tagging.encode())
+	setHeader(&req.Header, api.HeaderIfMatch, input.IfMatch)
+	setHeader(&req.Header, api.HeaderIfNoneMatch, input.IfNoneMatch)
+	setMetadata(&req.Header, input.Metadata)
+	checksums.setHeaders(&req.Header)
+	req.SetBodyRaw(input.Body)
+
+	if err := c.doBucket(ctx, input.Bucket, req, resp); err != nil {
+		return nil, conditionalError(err)
+	}
+
+	output := &PutObjectOutput{
+		ETag:         string(resp.Header.Peek(api.HeaderETag)),
+		VersionID:    string(resp.Header.Peek(api.HeaderXAmzVersionID)),
+		Checksums:    checksumsFromHeaders(&resp.Header).orSent(checksums),
+		ChecksumType: ChecksumType(resp.Header.Peek(api.HeaderXAmzChecksumType)),
+	}
+
+	return output, nil
+}
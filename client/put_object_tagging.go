@@ -0,0 +1,60 @@
+package client
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+
+	"github.com/lvjp/s3hobby/pkg/s3/api"
+	"github.com/lvjp/s3hobby/pkg/s3/signing"
+
+	"github.com/valyala/fasthttp"
+)
+
+type PutObjectTaggingInput struct {
+	Bucket    string
+	Key       string
+	VersionID string
+
+	// TagSet replaces the tags of the object, it is validated before sending.
+	TagSet TagSet
+}
+
+type PutObjectTaggingOutput struct {
+	VersionID string
+}
+
+func (c *Client) PutObjectTagging(ctx context.Context, input *PutObjectTaggingInput) (*PutObjectTaggingOutput, error) {
+	if err := input.TagSet.Validate(); err != nil {
+		return nil, fmt.Errorf("PutObjectTagging: %w", err)
+	}
+
+	body, err := xml.Marshal(api.Tagging{TagSet: input.TagSet.toAPI()})
+	if err != nil {
+		return nil, fmt.Errorf("PutObjectTagging: cannot marshal tags: %w", err)
+	}
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(resp)
+
+	if err := c.setTaggingRequest(req, fasthttp.MethodPut, input.Bucket, input.Key, input.VersionID); err != nil {
+		return nil, err
+	}
+
+	req.SetBodyRaw(body)
+
+	// S3 requires an integrity check of the body, whatever the client options.
+	if err := signing.SetContentMD5(signing.NewFastHTTPRequest(req)); err != nil {
+		return nil, err
+	}
+
+	if err := c.doBucket(ctx, input.Bucket, req, resp); err != nil {
+		return nil, err
+	}
+
+	return &PutObjectTaggingOutput{
+		VersionID: string(resp.Header.Peek(api.HeaderXAmzVersionID)),
+	}, nil
+}
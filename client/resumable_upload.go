@@ -0,0 +1,518 @@
+package client
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/lvjp/s3hobby/pkg/s3/api"
+
+	"github.com/valyala/fasthttp"
+)
+
+// ErrSourceChanged reports a file modified since the checkpoint of its upload
+// was written, the upload cannot be resumed. It has been aborted and its
+// checkpoint removed.
+var ErrSourceChanged = errors.New("Uploader: source file changed since the checkpoint")
+
+// checkpointSuffix is appended to the file path to get the default checkpoint
+// path.
+const checkpointSuffix = ".s3upload"
+
+type UploadFileInput struct {
+	Bucket string
+	Key    string
+
+	// Path is the file to upload.
+	Path string
+
+	// CheckpointPath is the file holding the state of the upload, so that it
+	// can be resumed after a failure. It defaults to Path followed by
+	// ".s3upload" and is removed once the upload is complete.
+	CheckpointPath string
+
+	ContentType  string
+	Metadata     map[string]string
+	StorageClass string
+	Tagging      TagSet
+
+	// ChecksumAlgorithm and ChecksumType have the same defaults as for Upload.
+	ChecksumAlgorithm ChecksumAlgorithm
+	ChecksumType      ChecksumType
+
+	// RestartChanged starts a new upload when the file changed since the
+	// checkpoint, instead of returning ErrSourceChanged.
+	RestartChanged bool
+}
+
+// uploadCheckpoint is the state of a resumable upload persisted to disk.
+type uploadCheckpoint struct {
+	Bucket   string
+	Key      string
+	UploadID string
+
+	PartSize          int64
+	ChecksumAlgorithm ChecksumAlgorithm
+	ChecksumType      ChecksumType
+
+	Source sourceFingerprint
+
+	// Parts are the parts known to be uploaded, unsorted.
+	Parts []CompletedPart
+}
+
+// sourceFingerprint identifies a version of the uploaded file.
+type sourceFingerprint struct {
+	Size    int64
+	ModTime time.Time
+}
+
+// UploadFile uploads a file like Upload, except that a failed multipart upload
+// is not aborted: its state is kept in a checkpoint file instead, and the next
+// call for the same file resumes it. The parts reported by ListParts are then
+// trusted over the checkpoint, which may miss the last uploaded parts, once
+// their checksum matches the file content: the other ones are uploaded again.
+//
+// A file whose size or modification time changed since the checkpoint is not
+// resumed: the upload is aborted and the checkpoint removed, then
+// ErrSourceChanged is returned unless RestartChanged is set.
+func (u *Uploader) UploadFile(ctx context.Context, input *UploadFileInput) (*UploadOutput, error) {
+	file, err := os.Open(input.Path)
+	if err != nil {
+		return nil, fmt.Errorf("Uploader: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("Uploader: %w", err)
+	}
+
+	source := sourceFingerprint{Size: info.Size(), ModTime: info.ModTime().UTC()}
+
+	checkpointPath := input.CheckpointPath
+	if checkpointPath == "" {
+		checkpointPath = input.Path + checkpointSuffix
+	}
+
+	checkpoint, found, err := u.resumeCheckpoint(ctx, input, file, source, checkpointPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if !found {
+		sizes, err := u.partSizes(file)
+		if err != nil {
+			return nil, err
+		}
+
+		// Small files do not need to be resumed.
+		if sizes.singlePart() {
+			return u.uploadSmallFile(ctx, input, file, checkpointPath)
+		}
+
+		if checkpoint, err = u.createCheckpoint(ctx, input, source, sizes.base); err != nil {
+			return nil, err
+		}
+	}
+
+	upload := &fileUpload{
+		uploader:       u,
+		file:           file,
+		checkpoint:     checkpoint,
+		checkpointPath: checkpointPath,
+	}
+
+	if err := upload.saveCheckpoint(); err != nil {
+		return nil, fmt.Errorf("Uploader: %w", err)
+	}
+
+	output, err := upload.run(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Uploader: upload %s interrupted, %s allows to resume it: %w", checkpoint.UploadID, checkpointPath, err)
+	}
+
+	if err := os.Remove(checkpointPath); err != nil {
+		return nil, fmt.Errorf("Uploader: cannot remove checkpoint: %w", err)
+	}
+
+	return output, nil
+}
+
+// uploadSmallFile uploads a file fitting in a part with PutObject. The
+// checkpoint left by a previous call, whose upload does not exist anymore, is
+// removed: the file might have been sent in parts of a smaller size.
+func (u *Uploader) uploadSmallFile(ctx context.Context, input *UploadFileInput, file *os.File, checkpointPath string) (*UploadOutput, error) {
+	output, err := u.Upload(ctx, &UploadInput{
+		Bucket:            input.Bucket,
+		Key:               input.Key,
+		Body:              file,
+		ContentType:       input.ContentType,
+		Metadata:          input.Metadata,
+		StorageClass:      input.StorageClass,
+		Tagging:           input.Tagging,
+		ChecksumAlgorithm: input.ChecksumAlgorithm,
+		ChecksumType:      input.ChecksumType,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.Remove(checkpointPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("Uploader: cannot remove checkpoint: %w", err)
+	}
+
+	return output, nil
+}
+
+// resumeCheckpoint loads the checkpoint of a previous call and reconciles it
+// with S3. It is not found when the upload does not exist anymore.
+func (u *Uploader) resumeCheckpoint(ctx context.Context, input *UploadFileInput, file io.ReaderAt, source sourceFingerprint, path string) (*uploadCheckpoint, bool, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
+
+	if err != nil {
+		return nil, false, fmt.Errorf("Uploader: cannot read checkpoint: %w", err)
+	}
+
+	var checkpoint uploadCheckpoint
+	if err := json.Unmarshal(content, &checkpoint); err != nil {
+		return nil, false, fmt.Errorf("Uploader: cannot parse checkpoint %s: %w", path, err)
+	}
+
+	if err := checkpoint.check(input, source); err != nil {
+		if !errors.Is(err, ErrSourceChanged) {
+			return nil, false, err
+		}
+
+		if discardErr := u.discardCheckpoint(ctx, &checkpoint, path); discardErr != nil {
+			return nil, false, fmt.Errorf("%w: %w", err, discardErr)
+		}
+
+		if !input.RestartChanged {
+			return nil, false, err
+		}
+
+		return nil, false, nil
+	}
+
+	found, err := u.reconcile(ctx, &checkpoint, file)
+	if err != nil || !found {
+		return nil, false, err
+	}
+
+	return &checkpoint, true, nil
+}
+
+// discardCheckpoint aborts the upload of the checkpoint, so that its parts are
+// not left behind, and removes the checkpoint.
+func (u *Uploader) discardCheckpoint(ctx context.Context, checkpoint *uploadCheckpoint, path string) error {
+	_, err := u.client.AbortMultipartUpload(ctx, &AbortMultipartUploadInput{
+		Bucket:   checkpoint.Bucket,
+		Key:      checkpoint.Key,
+		UploadID: checkpoint.UploadID,
+	})
+	if err != nil && !isNotFound(err) {
+		return fmt.Errorf("Uploader: cannot abort upload %s: %w", checkpoint.UploadID, err)
+	}
+
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("Uploader: cannot remove checkpoint: %w", err)
+	}
+
+	return nil
+}
+
+func (u *Uploader) createCheckpoint(ctx context.Context, input *UploadFileInput, source sourceFingerprint, partSize int64) (*uploadCheckpoint, error) {
+	algorithm, checksumType, err := checksumSettings(input.ChecksumAlgorithm, input.ChecksumType)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := u.client.CreateMultipartUpload(ctx, &CreateMultipartUploadInput{
+		Bucket:            input.Bucket,
+		Key:               input.Key,
+		ContentType:       input.ContentType,
+		Metadata:          input.Metadata,
+		StorageClass:      input.StorageClass,
+		Tagging:           input.Tagging,
+		ChecksumAlgorithm: algorithm,
+		ChecksumType:      checksumType,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &uploadCheckpoint{
+		Bucket:            input.Bucket,
+		Key:               input.Key,
+		UploadID:          created.UploadID,
+		PartSize:          partSize,
+		ChecksumAlgorithm: algorithm,
+		ChecksumType:      checksumType,
+		Source:            source,
+	}, nil
+}
+
+// reconcile replaces the checkpoint parts by the ones listed by S3 and still
+// matching the file. The upload is not found when it has been aborted or
+// completed meanwhile.
+func (u *Uploader) reconcile(ctx context.Context, checkpoint *uploadCheckpoint, file io.ReaderAt) (bool, error) {
+	known := make(map[int]CompletedPart, len(checkpoint.Parts))
+	for _, part := range checkpoint.Parts {
+		known[part.PartNumber] = part
+	}
+
+	var parts []CompletedPart
+
+	for part, err := range u.client.Parts(ctx, &ListPartsInput{
+		Bucket:   checkpoint.Bucket,
+		Key:      checkpoint.Key,
+		UploadID: checkpoint.UploadID,
+	}) {
+		var apiErr *api.Error
+		if errors.As(err, &apiErr) && apiErr.StatusCode == fasthttp.StatusNotFound {
+			return false, nil
+		}
+
+		if err != nil {
+			return false, err
+		}
+
+		// Parts of an unexpected size are uploaded again.
+		if part.Size != checkpoint.partSize(part.PartNumber) {
+			continue
+		}
+
+		completed := part.CompletedPart()
+
+		// The checksums are required by the completion.
+		if completed.Checksums.Get(checkpoint.ChecksumAlgorithm) == "" {
+			if previous, ok := known[part.PartNumber]; ok && previous.ETag == part.ETag {
+				completed.Checksums = previous.Checksums
+			} else {
+				continue
+			}
+		}
+
+		// Parts of a content rewritten since their upload are uploaded again.
+		matches, err := u.matchesFile(file, checkpoint, completed)
+		if err != nil {
+			return false, err
+		}
+
+		if !matches {
+			continue
+		}
+
+		parts = append(parts, completed)
+	}
+
+	checkpoint.Parts = parts
+
+	return true, nil
+}
+
+// matchesFile reports whether the checksum of the part matches the content of
+// the file it was read from.
+func (u *Uploader) matchesFile(file io.ReaderAt, checkpoint *uploadCheckpoint, part CompletedPart) (bool, error) {
+	buffer := u.getBuffer(checkpoint.partSize(part.PartNumber))
+	defer u.buffers.Put(buffer)
+
+	if _, err := file.ReadAt(*buffer, int64(part.PartNumber-1)*checkpoint.PartSize); err != nil {
+		return false, fmt.Errorf("Uploader: cannot read part %d: %w", part.PartNumber, err)
+	}
+
+	checksum, err := computeChecksum(checkpoint.ChecksumAlgorithm, *buffer)
+	if err != nil {
+		return false, err
+	}
+
+	return checksum == part.Checksums.Get(checkpoint.ChecksumAlgorithm), nil
+}
+
+// check refuses to resume the upload of another object or file version.
+func (c *uploadCheckpoint) check(input *UploadFileInput, source sourceFingerprint) error {
+	if c.Bucket != input.Bucket || c.Key != input.Key {
+		return fmt.Errorf("Uploader: checkpoint of upload %s targets %s/%s", c.UploadID, c.Bucket, c.Key)
+	}
+
+	if c.PartSize < 1 {
+		return fmt.Errorf("Uploader: checkpoint of upload %s has an invalid part size", c.UploadID)
+	}
+
+	if c.Source.Size != source.Size || !c.Source.ModTime.Equal(source.ModTime) {
+		return fmt.Errorf("%w: upload %s", ErrSourceChanged, c.UploadID)
+	}
+
+	return nil
+}
+
+func (c *uploadCheckpoint) partCount() int {
+	return int((c.Source.Size + c.PartSize - 1) / c.PartSize)
+}
+
+func (c *uploadCheckpoint) partSize(partNumber int) int64 {
+	return min(c.PartSize, c.Source.Size-int64(partNumber-1)*c.PartSize)
+}
+
+// fileUpload holds the state of an UploadFile.
+type fileUpload struct {
+	uploader       *Uploader
+	file           io.ReaderAt
+	checkpointPath string
+
+	mu         sync.Mutex
+	checkpoint *uploadCheckpoint
+}
+
+func (f *fileUpload) run(ctx context.Context) (*UploadOutput, error) {
+	if count := f.checkpoint.partCount(); count > MaxPartNumber {
+		return nil, fmt.Errorf("file does not fit in %d parts of %d bytes", MaxPartNumber, f.checkpoint.PartSize)
+	}
+
+	if err := f.uploadParts(ctx); err != nil {
+		return nil, err
+	}
+
+	checkpoint := f.checkpoint
+	parts := slices.SortedFunc(slices.Values(checkpoint.Parts), func(a, b CompletedPart) int {
+		return cmp.Compare(a.PartNumber, b.PartNumber)
+	})
+
+	var checksums Checksums
+
+	if checkpoint.ChecksumType == ChecksumTypeFullObject {
+		checksum, err := fullObjectChecksum(checkpoint.ChecksumAlgorithm, parts, checkpoint.partSize)
+		if err != nil {
+			return nil, err
+		}
+
+		*checksums.field(checkpoint.ChecksumAlgorithm) = checksum
+	}
+
+	completed, err := f.uploader.client.CompleteMultipartUpload(ctx, &CompleteMultipartUploadInput{
+		Bucket:       checkpoint.Bucket,
+		Key:          checkpoint.Key,
+		UploadID:     checkpoint.UploadID,
+		Parts:        parts,
+		Checksums:    checksums,
+		ChecksumType: checkpoint.ChecksumType,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	output := &UploadOutput{
+		ETag:         completed.ETag,
+		VersionID:    completed.VersionID,
+		UploadID:     checkpoint.UploadID,
+		PartCount:    len(parts),
+		Checksums:    completed.Checksums,
+		ChecksumType: checkpoint.ChecksumType,
+	}
+
+	if checkpoint.ChecksumType == ChecksumTypeComposite {
+		if err := checkCompositeChecksum(checkpoint.ChecksumAlgorithm, parts, output); err != nil {
+			return nil, err
+		}
+	}
+
+	return output, nil
+}
+
+// uploadParts uploads the parts missing from the checkpoint, which is saved
+// after every uploaded part.
+func (f *fileUpload) uploadParts(ctx context.Context) error {
+	uploaded := make(map[int]bool, len(f.checkpoint.Parts))
+	for _, part := range f.checkpoint.Parts {
+		uploaded[part.PartNumber] = true
+	}
+
+	group := newTaskGroup(ctx, f.uploader.options.Concurrency)
+
+	for partNumber := 1; partNumber <= f.checkpoint.partCount(); partNumber++ {
+		if uploaded[partNumber] {
+			continue
+		}
+
+		if !group.Acquire() {
+			break
+		}
+
+		group.Go(func(ctx context.Context) error {
+			return f.uploadPart(ctx, partNumber)
+		})
+	}
+
+	return group.Wait()
+}
+
+func (f *fileUpload) uploadPart(ctx context.Context, partNumber int) error {
+	checkpoint := f.checkpoint
+	size := checkpoint.partSize(partNumber)
+
+	buffer := f.uploader.getBuffer(size)
+	defer f.uploader.buffers.Put(buffer)
+
+	if _, err := f.file.ReadAt(*buffer, int64(partNumber-1)*checkpoint.PartSize); err != nil {
+		return fmt.Errorf("cannot read part %d: %w", partNumber, err)
+	}
+
+	output, err := f.uploader.client.UploadPart(ctx, &UploadPartInput{
+		Bucket:            checkpoint.Bucket,
+		Key:               checkpoint.Key,
+		UploadID:          checkpoint.UploadID,
+		PartNumber:        partNumber,
+		Body:              *buffer,
+		ChecksumAlgorithm: checkpoint.ChecksumAlgorithm,
+	})
+	if err != nil {
+		return fmt.Errorf("part %d: %w", partNumber, err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	checkpoint.Parts = append(checkpoint.Parts, output.CompletedPart())
+
+	return f.saveCheckpointLocked()
+}
+
+func (f *fileUpload) saveCheckpoint() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.saveCheckpointLocked()
+}
+
+// saveCheckpointLocked replaces the checkpoint file atomically, so that a
+// crash never leaves a truncated one.
+func (f *fileUpload) saveCheckpointLocked() error {
+	content, err := json.Marshal(f.checkpoint)
+	if err != nil {
+		return fmt.Errorf("cannot marshal checkpoint: %w", err)
+	}
+
+	temporary := f.checkpointPath + ".tmp"
+
+	if err := os.WriteFile(temporary, content, 0o600); err != nil { //nolint:mnd // Owner read and write
+		return fmt.Errorf("cannot write checkpoint: %w", err)
+	}
+
+	if err := os.Rename(temporary, f.checkpointPath); err != nil {
+		return fmt.Errorf("cannot write checkpoint: %w", err)
+	}
+
+	return nil
+}
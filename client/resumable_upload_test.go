@@ -0,0 +1,260 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lvjp/s3hobby/pkg/s3/api"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+// testFileUploaderOptions sends one part at a time, so that failures are
+// deterministic.
+var testFileUploaderOptions = UploaderOptions{PartSize: MinPartSize, Concurrency: 1}
+
+func writeTestFile(t *testing.T, size int64) (string, []byte) {
+	t.Helper()
+
+	content := bytes.Repeat([]byte("0123456789abcdef"), int(size/16))
+	path := filepath.Join(t.TempDir(), "object")
+
+	require.NoError(t, os.WriteFile(path, content, 0o600))
+
+	return path, content
+}
+
+func TestUploadFile(t *testing.T) {
+	path, content := writeTestFile(t, 2*MinPartSize+MinPartSize/2)
+
+	fullObject, err := computeChecksum(ChecksumAlgorithmCRC32, content)
+	require.NoError(t, err)
+
+	input := &UploadFileInput{Bucket: "examplebucket", Key: "example-object", Path: path}
+
+	store := &fakeMultipartStore{failPart: 2}
+	server := newMultipartServer(t, store)
+	uploader := newTestHelper(t, server, NewUploader, testFileUploaderOptions)
+
+	_, err = uploader.UploadFile(context.Background(), input)
+	require.ErrorContains(t, err, "Uploader: upload UPLOAD interrupted, "+path+".s3upload allows to resume it: part 2: s3: 500 InternalError")
+	assert.Empty(t, server.requests(fasthttp.MethodDelete, "uploadId"))
+	require.FileExists(t, path+checkpointSuffix)
+
+	// Simulate a crash between the upload of the first part and the checkpoint.
+	raw, err := os.ReadFile(path + checkpointSuffix)
+	require.NoError(t, err)
+
+	var checkpoint uploadCheckpoint
+	require.NoError(t, json.Unmarshal(raw, &checkpoint))
+	assert.Len(t, checkpoint.Parts, 1)
+
+	checkpoint.Parts = nil
+	raw, err = json.Marshal(checkpoint)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path+checkpointSuffix, raw, 0o600))
+
+	store.failPart = 0
+	server.httpClient.requests = nil
+
+	output, err := uploader.UploadFile(context.Background(), input)
+	require.NoError(t, err)
+
+	assert.Equal(t, &UploadOutput{
+		ETag:         `"multipart-3"`,
+		UploadID:     "UPLOAD",
+		PartCount:    3,
+		Checksums:    Checksums{CRC32: fullObject},
+		ChecksumType: ChecksumTypeFullObject,
+	}, output)
+
+	assert.Empty(t, server.requests(fasthttp.MethodPost, "uploads"), "the upload must be resumed")
+	assert.Len(t, server.requests(fasthttp.MethodGet, "uploadId"), 1)
+
+	uploadedParts := server.requests(fasthttp.MethodPut, "partNumber")
+	require.Len(t, uploadedParts, 2, "the first part must not be uploaded again")
+	assert.Equal(t, "2", string(uploadedParts[0].URI().QueryArgs().Peek("partNumber")))
+
+	complete := server.requests(fasthttp.MethodPost, "uploadId")
+	require.Len(t, complete, 1)
+	assert.Equal(t, fullObject, string(complete[0].Header.Peek(api.HeaderXAmzChecksumCrc32)))
+
+	assert.True(t, bytes.Equal(content, store.object()), "uploaded object differs from the file")
+	assert.NoFileExists(t, path+checkpointSuffix)
+}
+
+func TestUploadFileSourceChanged(t *testing.T) {
+	testCases := map[string]func(t *testing.T, path string){
+		"modification time": func(t *testing.T, path string) {
+			require.NoError(t, os.Chtimes(path, time.Time{}, time.Now().Add(time.Hour)))
+		},
+		"size": func(t *testing.T, path string) {
+			require.NoError(t, os.Truncate(path, 2*MinPartSize))
+		},
+	}
+
+	for name, change := range testCases {
+		t.Run(name, func(t *testing.T) {
+			path, _ := writeTestFile(t, 2*MinPartSize+MinPartSize/2)
+
+			store := &fakeMultipartStore{failPart: 2}
+			server := newMultipartServer(t, store)
+			uploader := newTestHelper(t, server, NewUploader, testFileUploaderOptions)
+
+			input := &UploadFileInput{Bucket: "examplebucket", Key: "example-object", Path: path, CheckpointPath: filepath.Join(t.TempDir(), "checkpoint")}
+
+			_, err := uploader.UploadFile(context.Background(), input)
+			require.Error(t, err)
+
+			change(t, path)
+			server.httpClient.requests = nil
+
+			_, err = uploader.UploadFile(context.Background(), input)
+			require.ErrorIs(t, err, ErrSourceChanged)
+			assert.Len(t, server.httpClient.requests, 1)
+			assert.Len(t, server.requests(fasthttp.MethodDelete, "uploadId"), 1, "the stale upload must be aborted")
+			assert.NoFileExists(t, input.CheckpointPath)
+		})
+	}
+}
+
+func TestUploadFileRestartChanged(t *testing.T) {
+	path, _ := writeTestFile(t, 2*MinPartSize+MinPartSize/2)
+
+	store := &fakeMultipartStore{failPart: 2}
+	server := newMultipartServer(t, store)
+	uploader := newTestHelper(t, server, NewUploader, testFileUploaderOptions)
+
+	input := &UploadFileInput{Bucket: "examplebucket", Key: "example-object", Path: path, RestartChanged: true}
+
+	_, err := uploader.UploadFile(context.Background(), input)
+	require.Error(t, err)
+
+	content := bytes.Repeat([]byte("fedcba9876543210"), int(3*MinPartSize/16))
+	require.NoError(t, os.WriteFile(path, content, 0o600))
+
+	store.failPart = 0
+	server.httpClient.requests = nil
+
+	_, err = uploader.UploadFile(context.Background(), input)
+	require.NoError(t, err)
+
+	assert.Len(t, server.requests(fasthttp.MethodDelete, "uploadId"), 1, "the stale upload must be aborted")
+	assert.Len(t, server.requests(fasthttp.MethodPost, "uploads"), 1, "a new upload must be created")
+	assert.Len(t, server.requests(fasthttp.MethodPut, "partNumber"), 3)
+	assert.True(t, bytes.Equal(content, store.object()), "uploaded object differs from the file")
+	assert.NoFileExists(t, path+checkpointSuffix)
+}
+
+func TestUploadFileContentRewritten(t *testing.T) {
+	path, _ := writeTestFile(t, 2*MinPartSize+MinPartSize/2)
+
+	store := &fakeMultipartStore{failPart: 2}
+	server := newMultipartServer(t, store)
+	uploader := newTestHelper(t, server, NewUploader, testFileUploaderOptions)
+
+	input := &UploadFileInput{Bucket: "examplebucket", Key: "example-object", Path: path}
+
+	_, err := uploader.UploadFile(context.Background(), input)
+	require.Error(t, err)
+
+	// Rewrite the uploaded first part in place, keeping the size and the
+	// modification time like rsync or touch -r.
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+
+	file, err := os.OpenFile(path, os.O_WRONLY, 0)
+	require.NoError(t, err)
+
+	_, err = file.WriteAt([]byte("changed"), 42)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+	require.NoError(t, os.Chtimes(path, time.Time{}, info.ModTime()))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	store.failPart = 0
+	server.httpClient.requests = nil
+
+	_, err = uploader.UploadFile(context.Background(), input)
+	require.NoError(t, err)
+
+	uploadedParts := server.requests(fasthttp.MethodPut, "partNumber")
+	require.Len(t, uploadedParts, 3, "the rewritten part must be uploaded again")
+	assert.True(t, bytes.Equal(content, store.object()), "uploaded object differs from the file")
+}
+
+func TestUploadFileUploadGone(t *testing.T) {
+	path, content := writeTestFile(t, 2*MinPartSize)
+
+	store := &fakeMultipartStore{failPart: 2}
+	server := newMultipartServer(t, store)
+	uploader := newTestHelper(t, server, NewUploader, testFileUploaderOptions)
+
+	input := &UploadFileInput{Bucket: "examplebucket", Key: "example-object", Path: path}
+
+	_, err := uploader.UploadFile(context.Background(), input)
+	require.Error(t, err)
+
+	store.failPart = 0
+	store.noSuchUpload = true
+	server.httpClient.requests = nil
+
+	_, err = uploader.UploadFile(context.Background(), input)
+	require.NoError(t, err)
+
+	assert.Len(t, server.requests(fasthttp.MethodPost, "uploads"), 1, "a new upload must be created")
+	assert.Len(t, server.requests(fasthttp.MethodPut, "partNumber"), 2)
+	assert.True(t, bytes.Equal(content, store.object()), "uploaded object differs from the file")
+}
+
+func TestUploadFileUploadGoneSmall(t *testing.T) {
+	path, content := writeTestFile(t, MinPartSize+MinPartSize/2)
+
+	store := &fakeMultipartStore{failPart: 2}
+	server := newMultipartServer(t, store)
+	uploader := newTestHelper(t, server, NewUploader, testFileUploaderOptions)
+
+	input := &UploadFileInput{Bucket: "examplebucket", Key: "example-object", Path: path}
+
+	_, err := uploader.UploadFile(context.Background(), input)
+	require.Error(t, err)
+	require.FileExists(t, path+checkpointSuffix)
+
+	// The file now fits in a part.
+	uploader = newTestHelper(t, server, NewUploader, UploaderOptions{PartSize: 2 * MinPartSize, Concurrency: 1})
+	store.noSuchUpload = true
+	server.httpClient.requests = nil
+
+	output, err := uploader.UploadFile(context.Background(), input)
+	require.NoError(t, err)
+
+	assert.Empty(t, output.UploadID)
+	assert.Empty(t, server.requests(fasthttp.MethodPost, "uploads"))
+	assert.Equal(t, content, store.objects["/example-object"])
+	assert.NoFileExists(t, path+checkpointSuffix, "the checkpoint of the gone upload must be removed")
+}
+
+func TestUploadFileSmall(t *testing.T) {
+	path, content := writeTestFile(t, 1024)
+
+	store := &fakeMultipartStore{}
+	server := newMultipartServer(t, store)
+	uploader := newTestHelper(t, server, NewUploader, testFileUploaderOptions)
+
+	output, err := uploader.UploadFile(context.Background(), &UploadFileInput{Bucket: "examplebucket", Key: "example-object", Path: path})
+	require.NoError(t, err)
+
+	assert.Empty(t, output.UploadID)
+	assert.Len(t, server.httpClient.requests, 1)
+	assert.Equal(t, content, store.objects["/example-object"])
+	assert.NoFileExists(t, path+checkpointSuffix)
+}
@@ -0,0 +1,74 @@
+package client
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lvjp/s3hobby/pkg/s3/api"
+	"github.com/lvjp/s3hobby/pkg/s3/signing"
+
+	"github.com/valyala/fasthttp"
+)
+
+// ClockSkew returns the offset currently applied to the local clock when
+// signing requests for the given endpoint host, like "s3.us-east-1.amazonaws.com"
+// for all the buckets of the default endpoint.
+func (c *Client) ClockSkew(host string) time.Duration {
+	return c.clockSkews.get(host)
+}
+
+// skewHost returns the host whose clock skew applies to a request host: the
+// virtual hosted buckets share the skew of their endpoint, and the directory
+// buckets the one of their zonal endpoint.
+func (c *Client) skewHost(host string) string {
+	if strings.HasSuffix(host, "."+c.endpoint.Host) {
+		return c.endpoint.Host
+	}
+
+	if i := strings.Index(host, "."+zonalEndpointPrefix); i > 0 {
+		return host[i+1:]
+	}
+
+	return host
+}
+
+func (c *Client) signingTime(host string) signing.SigningTime {
+	return signing.SigningTimeOf(c.options.Clock.Now().Add(c.clockSkews.get(host)))
+}
+
+// updateClockSkew records the offset between the server Date header and the
+// local clock. It reports whether an offset could be computed.
+func (c *Client) updateClockSkew(host string, resp *fasthttp.Response) bool {
+	serverTime, err := fasthttp.ParseHTTPDate(resp.Header.Peek(api.HeaderDate))
+	if err != nil {
+		return false
+	}
+
+	c.clockSkews.set(host, serverTime.Sub(c.options.Clock.Now()))
+
+	return true
+}
+
+type clockSkews struct {
+	mu sync.RWMutex
+	v  map[string]time.Duration
+}
+
+func (cs *clockSkews) get(host string) time.Duration {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	return cs.v[host]
+}
+
+func (cs *clockSkews) set(host string, skew time.Duration) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	if cs.v == nil {
+		cs.v = make(map[string]time.Duration)
+	}
+
+	cs.v[host] = skew
+}
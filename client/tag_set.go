@@ -0,0 +1,126 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/lvjp/s3hobby/pkg/s3/api"
+)
+
+const (
+	// MaxObjectTags is the largest number of tags of an object.
+	MaxObjectTags = 10
+
+	// MaxTagKeyLength and MaxTagValueLength are counted in Unicode characters.
+	MaxTagKeyLength   = 128
+	MaxTagValueLength = 256
+)
+
+// ErrInvalidTagSet is returned when a tag set does not match the S3 limits, it
+// is detected before sending any request.
+var ErrInvalidTagSet = errors.New("client: invalid tag set")
+
+type Tag struct {
+	Key   string
+	Value string
+}
+
+// TagSet holds the tags of an object, in the order they are sent.
+type TagSet []Tag
+
+// Get returns the value of the tag, and whether it is set.
+func (s TagSet) Get(key string) (string, bool) {
+	for _, tag := range s {
+		if tag.Key == key {
+			return tag.Value, true
+		}
+	}
+
+	return "", false
+}
+
+// Validate checks the S3 limits: at most MaxObjectTags tags with unique keys,
+// made of letters, numbers, spaces and the "+-=._:/@" symbols. The keys are
+// not empty and cannot use the reserved "aws:" prefix.
+func (s TagSet) Validate() error {
+	if len(s) > MaxObjectTags {
+		return fmt.Errorf("%w: %d tags, at most %d are allowed", ErrInvalidTagSet, len(s), MaxObjectTags)
+	}
+
+	keys := make(map[string]bool, len(s))
+
+	for _, tag := range s {
+		switch {
+		case tag.Key == "" || utf8.RuneCountInString(tag.Key) > MaxTagKeyLength:
+			return fmt.Errorf("%w: key %q must have between 1 and %d characters", ErrInvalidTagSet, tag.Key, MaxTagKeyLength)
+		case utf8.RuneCountInString(tag.Value) > MaxTagValueLength:
+			return fmt.Errorf("%w: value of %q must have at most %d characters", ErrInvalidTagSet, tag.Key, MaxTagValueLength)
+		case strings.HasPrefix(strings.ToLower(tag.Key), "aws:"):
+			return fmt.Errorf("%w: key %q uses the reserved aws: prefix", ErrInvalidTagSet, tag.Key)
+		case !validTagString(tag.Key):
+			return fmt.Errorf("%w: key %q has forbidden characters", ErrInvalidTagSet, tag.Key)
+		case !validTagString(tag.Value):
+			return fmt.Errorf("%w: value of %q has forbidden characters", ErrInvalidTagSet, tag.Key)
+		case keys[tag.Key]:
+			return fmt.Errorf("%w: duplicate key %q", ErrInvalidTagSet, tag.Key)
+		}
+
+		keys[tag.Key] = true
+	}
+
+	return nil
+}
+
+// encode returns the value of the x-amz-tagging header, the tags being URL
+// query parameters.
+func (s TagSet) encode() string {
+	var ret strings.Builder
+
+	for index, tag := range s {
+		if index > 0 {
+			ret.WriteByte('&')
+		}
+
+		ret.WriteString(url.QueryEscape(tag.Key))
+		ret.WriteByte('=')
+		ret.WriteString(url.QueryEscape(tag.Value))
+	}
+
+	return ret.String()
+}
+
+func (s TagSet) toAPI() []api.Tag {
+	ret := make([]api.Tag, 0, len(s))
+	for _, tag := range s {
+		ret = append(ret, api.Tag{Key: tag.Key, Value: tag.Value})
+	}
+
+	return ret
+}
+
+func tagSetFromAPI(tags []api.Tag) TagSet {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	ret := make(TagSet, 0, len(tags))
+	for _, tag := range tags {
+		ret = append(ret, Tag{Key: tag.Key, Value: tag.Value})
+	}
+
+	return ret
+}
+
+func validTagString(value string) bool {
+	for _, r := range value {
+		if !unicode.IsLetter(r) && !unicode.IsNumber(r) && r != ' ' && !strings.ContainsRune("+-=._:/@", r) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,103 @@
+package client
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTagSetValidate(t *testing.T) {
+	tooMany := make(TagSet, 0, MaxObjectTags+1)
+	for i := range MaxObjectTags + 1 {
+		tooMany = append(tooMany, Tag{Key: string(rune('a' + i))})
+	}
+
+	testCases := []struct {
+		name    string
+		tagSet  TagSet
+		wantErr string
+	}{
+		{name: "empty"},
+		{
+			name:   "valid",
+			tagSet: TagSet{{Key: "project", Value: "hobby s3"}, {Key: "owner", Value: "me@example.com"}, {Key: "path", Value: "a/b_c-d+e=f:g.h"}},
+		},
+		{
+			name:   "unicode",
+			tagSet: TagSet{{Key: strings.Repeat("é", MaxTagKeyLength), Value: strings.Repeat("日", MaxTagValueLength)}},
+		},
+		{
+			name:    "too many tags",
+			tagSet:  tooMany,
+			wantErr: "client: invalid tag set: 11 tags, at most 10 are allowed",
+		},
+		{
+			name:    "empty key",
+			tagSet:  TagSet{{Value: "value"}},
+			wantErr: `client: invalid tag set: key "" must have between 1 and 128 characters`,
+		},
+		{
+			name:    "long key",
+			tagSet:  TagSet{{Key: strings.Repeat("k", MaxTagKeyLength+1)}},
+			wantErr: "client: invalid tag set: key \"" + strings.Repeat("k", MaxTagKeyLength+1) + "\" must have between 1 and 128 characters",
+		},
+		{
+			name:    "long value",
+			tagSet:  TagSet{{Key: "key", Value: strings.Repeat("v", MaxTagValueLength+1)}},
+			wantErr: `client: invalid tag set: value of "key" must have at most 256 characters`,
+		},
+		{
+			name:    "reserved prefix",
+			tagSet:  TagSet{{Key: "AWS:owner"}},
+			wantErr: `client: invalid tag set: key "AWS:owner" uses the reserved aws: prefix`,
+		},
+		{
+			name:    "forbidden key",
+			tagSet:  TagSet{{Key: "a&b"}},
+			wantErr: `client: invalid tag set: key "a&b" has forbidden characters`,
+		},
+		{
+			name:    "forbidden value",
+			tagSet:  TagSet{{Key: "key", Value: "50%"}},
+			wantErr: `client: invalid tag set: value of "key" has forbidden characters`,
+		},
+		{
+			name:    "duplicate key",
+			tagSet:  TagSet{{Key: "key", Value: "1"}, {Key: "key", Value: "2"}},
+			wantErr: `client: invalid tag set: duplicate key "key"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.tagSet.Validate()
+			if tc.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorIs(t, err, ErrInvalidTagSet)
+			require.EqualError(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestTagSet(t *testing.T) {
+	tagSet := TagSet{{Key: "project", Value: "hobby s3"}, {Key: "path", Value: "a/b+c=d"}, {Key: "empty"}}
+
+	assert.Equal(t, "project=hobby+s3&path=a%2Fb%2Bc%3Dd&empty=", tagSet.encode())
+	assert.Empty(t, TagSet(nil).encode())
+
+	value, ok := tagSet.Get("path")
+	assert.True(t, ok)
+	assert.Equal(t, "a/b+c=d", value)
+
+	value, ok = tagSet.Get("empty")
+	assert.True(t, ok)
+	assert.Empty(t, value)
+
+	_, ok = tagSet.Get("missing")
+	assert.False(t, ok)
+}
@@ -0,0 +1,83 @@
+package client
+
+import (
+	"context"
+	"sync"
+)
+
+// taskGroup runs tasks concurrently, at most limit at a time. The first error
+// cancels the context of the tasks, the following ones are mostly caused by
+// the cancellation and are dropped.
+type taskGroup struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	slots  chan struct{}
+	wg     sync.WaitGroup
+
+	mu  sync.Mutex
+	err error
+}
+
+func newTaskGroup(ctx context.Context, limit int) *taskGroup {
+	ctx, cancel := context.WithCancel(ctx)
+
+	return &taskGroup{
+		ctx:    ctx,
+		cancel: cancel,
+		slots:  make(chan struct{}, limit),
+	}
+}
+
+// Acquire waits for a free slot. It reports false once the group failed or its
+// context is done, no slot is then taken.
+func (g *taskGroup) Acquire() bool {
+	if err := g.ctx.Err(); err != nil {
+		g.Fail(err)
+		return false
+	}
+
+	select {
+	case g.slots <- struct{}{}:
+		return true
+	case <-g.ctx.Done():
+		g.Fail(g.ctx.Err())
+		return false
+	}
+}
+
+// Release frees a slot acquired without running a task.
+func (g *taskGroup) Release() {
+	<-g.slots
+}
+
+// Go runs the task in a slot previously acquired, which is freed once the task
+// returns.
+func (g *taskGroup) Go(task func(ctx context.Context) error) {
+	g.wg.Go(func() {
+		defer g.Release()
+
+		if err := task(g.ctx); err != nil {
+			g.Fail(err)
+		}
+	})
+}
+
+// Fail records the error unless one is already recorded, and cancels the
+// running tasks.
+func (g *taskGroup) Fail(err error) {
+	g.mu.Lock()
+	if g.err == nil {
+		g.err = err
+	}
+	g.mu.Unlock()
+
+	g.cancel()
+}
+
+// Wait waits for the running tasks and returns the first error.
+func (g *taskGroup) Wait() error {
+	g.wg.Wait()
+	g.cancel()
+
+	return g.err
+}
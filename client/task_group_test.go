@@ -0,0 +1,74 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskGroup(t *testing.T) {
+	t.Run("limit", func(t *testing.T) {
+		group := newTaskGroup(context.Background(), 2)
+
+		var running, maxRunning, done atomic.Int32
+
+		for range 20 {
+			require.True(t, group.Acquire())
+
+			group.Go(func(context.Context) error {
+				current := running.Add(1)
+				defer running.Add(-1)
+
+				for {
+					previous := maxRunning.Load()
+					if current <= previous || maxRunning.CompareAndSwap(previous, current) {
+						break
+					}
+				}
+
+				done.Add(1)
+
+				return nil
+			})
+		}
+
+		require.NoError(t, group.Wait())
+		assert.Equal(t, int32(20), done.Load())
+		assert.LessOrEqual(t, maxRunning.Load(), int32(2))
+	})
+
+	t.Run("first error", func(t *testing.T) {
+		group := newTaskGroup(context.Background(), 3)
+		taskErr := errors.New("task failed")
+
+		for range 2 {
+			require.True(t, group.Acquire())
+			group.Go(func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			})
+		}
+
+		require.True(t, group.Acquire())
+		group.Go(func(context.Context) error {
+			return taskErr
+		})
+
+		require.Equal(t, taskErr, group.Wait())
+		assert.False(t, group.Acquire(), "no task must start after a failure")
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		group := newTaskGroup(ctx, 1)
+
+		assert.False(t, group.Acquire())
+		require.ErrorIs(t, group.Wait(), context.Canceled)
+	})
+}
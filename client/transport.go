@@ -0,0 +1,259 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/lvjp/s3hobby/pkg/s3/api"
+	"github.com/lvjp/s3hobby/pkg/s3/signing"
+
+	"github.com/valyala/fasthttp"
+)
+
+// Do signs and sends the request with the client credentials. Responses with
+// a status code of 300 and above are reported as *api.Error, wrapped in a
+// *SignatureMismatchError on SignatureDoesNotMatch.
+//
+// When the server answers RequestTimeTooSkewed, the clock skew of the endpoint
+// is updated from the response Date header and the request is signed again
+// and retried once. Its body is not copied for the retry, a body replaced by
+// the signer, like the streamed ones do, must then be set with SetBodyRaw.
+func (c *Client) Do(ctx context.Context, req *fasthttp.Request, resp *fasthttp.Response) error {
+	return c.do(ctx, req, resp, c.options.Credentials, signing.ServiceS3)
+}
+
+// doBucket sends a request targeting the bucket. Requests to directory buckets
+// are signed with the session credentials of the bucket.
+func (c *Client) doBucket(ctx context.Context, bucket string, req *fasthttp.Request, resp *fasthttp.Response) error {
+	if !isDirectoryBucket(bucket) {
+		return c.Do(ctx, req, resp)
+	}
+
+	credentials, err := c.sessionCredentials(ctx, bucket)
+	if err != nil {
+		return err
+	}
+
+	return c.do(ctx, req, resp, credentials, signing.ServiceS3Express)
+}
+
+func (c *Client) do(ctx context.Context, req *fasthttp.Request, resp *fasthttp.Response, credentials signing.Credentials, service string) error {
+	host := c.skewHost(string(req.URI().Host()))
+	snapshot := snapshotSigning(req)
+
+	err := c.signAndDo(ctx, host, req, resp, credentials, service)
+
+	var apiErr *api.Error
+	if !errors.As(err, &apiErr) || apiErr.Code != api.ErrorCodeRequestTimeTooSkewed {
+		return err
+	}
+
+	if !c.updateClockSkew(host, resp) {
+		return err
+	}
+
+	snapshot.restore(req)
+	resp.Reset()
+
+	return c.signAndDo(ctx, host, req, resp, credentials, service)
+}
+
+// signingHeaders are the headers set by the signers and SetContentMD5, or
+// changed by the streamed signers.
+var signingHeaders = []string{
+	api.HeaderAuthorization,
+	api.HeaderXAmzDate,
+	api.HeaderXAmzContentSHA256,
+	api.HeaderXAmzSecurityToken,
+	api.HeaderXAmzS3SessionToken,
+	api.HeaderContentMD5,
+	api.HeaderContentEncoding,
+	api.HeaderXAmzDecodedContentLength,
+}
+
+// signingSnapshot holds the state of a request changed by its signing, so
+// that it can be signed again. The body is not copied: only the streamed
+// signers replace it, by a new slice, and the original one set with
+// SetBodyRaw is kept as is.
+type signingSnapshot struct {
+	uri     fasthttp.URI
+	headers []snapshotHeader
+	body    []byte
+}
+
+type snapshotHeader struct {
+	name    string
+	value   string
+	present bool
+}
+
+func snapshotSigning(req *fasthttp.Request) *signingSnapshot {
+	snapshot := &signingSnapshot{body: req.Body()}
+	req.URI().CopyTo(&snapshot.uri)
+
+	names := slices.Clip(signingHeaders)
+
+	// The streamed signers move the trailing headers to the body.
+	for name := range strings.SplitSeq(string(req.Header.Peek(api.HeaderXAmzTrailer)), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	for _, name := range names {
+		value := req.Header.Peek(name)
+		snapshot.headers = append(snapshot.headers, snapshotHeader{name: name, value: string(value), present: value != nil})
+	}
+
+	return snapshot
+}
+
+// restore reverts the changes made by the signing of the request.
+func (s *signingSnapshot) restore(req *fasthttp.Request) {
+	s.uri.CopyTo(req.URI())
+
+	for _, header := range s.headers {
+		req.Header.Del(header.name)
+
+		if header.present {
+			req.Header.Set(header.name, header.value)
+		}
+	}
+
+	if body := req.Body(); len(body) != len(s.body) || len(body) > 0 && &body[0] != &s.body[0] {
+		req.SetBodyRaw(s.body)
+	}
+}
+
+func (c *Client) signAndDo(ctx context.Context, host string, req *fasthttp.Request, resp *fasthttp.Response, credentials signing.Credentials, service string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	signingReq := signing.NewFastHTTPRequest(req)
+
+	if c.options.ContentMD5 {
+		if err := signing.SetContentMD5(signingReq); err != nil {
+			return err
+		}
+	}
+
+	signed, err := c.options.Signer.Sign(signing.SigningArgs{
+		Request:     signingReq,
+		Credentials: credentials,
+		Region:      c.options.Region,
+		SigningTime: c.signingTime(host),
+		Service:     service,
+	})
+	if err != nil {
+		return err
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		err = c.options.HTTPClient.DoDeadline(req, resp, deadline)
+	} else {
+		err = c.options.HTTPClient.Do(req, resp)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if err := checkResponse(resp); err != nil {
+		var apiErr *api.Error
+		if errors.As(err, &apiErr) && apiErr.Code == api.ErrorCodeSignatureDoesNotMatch {
+			return &SignatureMismatchError{Err: apiErr, Signed: signed}
+		}
+
+		return err
+	}
+
+	if c.options.ContentMD5 {
+		return checkETag(req, resp)
+	}
+
+	return nil
+}
+
+func checkResponse(resp *fasthttp.Response) error {
+	statusCode := resp.StatusCode()
+	if statusCode < fasthttp.StatusMultipleChoices {
+		return nil
+	}
+
+	apiErr := &api.Error{
+		StatusCode:   statusCode,
+		BucketRegion: string(resp.Header.Peek(api.HeaderXAmzBucketRegion)),
+	}
+
+	// HEAD responses and some proxies do not provide any error document.
+	if body := resp.Body(); len(body) > 0 {
+		if err := xml.Unmarshal(body, apiErr); err != nil {
+			apiErr.Message = fasthttp.StatusMessage(statusCode)
+		}
+	}
+
+	if apiErr.Code == "" {
+		apiErr.Code = fasthttp.StatusMessage(statusCode)
+	}
+
+	return apiErr
+}
+
+// SignatureMismatchError reports a SignatureDoesNotMatch error along with what
+// the client signed, to be compared with what the server expected.
+type SignatureMismatchError struct {
+	Err    *api.Error
+	Signed signing.SigningResult
+}
+
+func (e *SignatureMismatchError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *SignatureMismatchError) Unwrap() error {
+	return e.Err
+}
+
+// Diff returns the line-level diff between the canonical request and the
+// string to sign expected by the server and the signed ones.
+func (e *SignatureMismatchError) Diff() string {
+	return e.Signed.DiffError(e.Err)
+}
+
+// checkErrorBody detects the errors reported with a 200 OK status by the
+// operations whose status is sent before the end of their processing, like
+// CompleteMultipartUpload.
+func checkErrorBody(resp *fasthttp.Response) error {
+	decoder := xml.NewDecoder(bytes.NewReader(resp.Body()))
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			// Not an XML document, the caller reports its own parsing error.
+			return nil //nolint:nilerr // See above
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			// Skip the XML declaration and the keep-alive white spaces.
+			continue
+		}
+
+		if start.Name.Local != "Error" {
+			return nil
+		}
+
+		apiErr := &api.Error{StatusCode: resp.StatusCode()}
+		if err := decoder.DecodeElement(apiErr, &start); err != nil {
+			return fmt.Errorf("client: cannot parse error document: %w", err)
+		}
+
+		return apiErr
+	}
+}
@@ -0,0 +1,148 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lvjp/s3hobby/pkg/s3/api"
+
+	"github.com/valyala/fasthttp"
+)
+
+const DefaultUpdateMaxAttempts = 5
+
+// conflictRetryDelay is the delay before retrying a write which conflicted
+// with a concurrent one, it is multiplied by the attempt number.
+const conflictRetryDelay = 100 * time.Millisecond
+
+type UpdateObjectInput struct {
+	Bucket string
+	Key    string
+
+	// Update returns the new content of the object from the current one,
+	// which is nil when the object does not exist. It is called again with
+	// the latest content when the object changes meanwhile, so it must not
+	// have side effects. Its error aborts the update and is returned as is.
+	Update func(current *GetObjectOutput) (*UpdatedContent, error)
+
+	// MaxAttempts defaults to DefaultUpdateMaxAttempts, it must be positive.
+	MaxAttempts int
+}
+
+// UpdatedContent is the content written by UpdateObject.
+type UpdatedContent struct {
+	Body        []byte
+	ContentType string
+	Metadata    map[string]string
+}
+
+type UpdateObjectOutput struct {
+	ETag      string
+	VersionID string
+
+	// Attempts is the number of read-modify-write cycles.
+	Attempts int
+}
+
+// UpdateObject updates an object with a read-modify-write cycle made safe by a
+// conditional write: the object is only overwritten when its ETag is still
+// the one read, or created when it still does not exist. The cycle is retried
+// when the object changed or was deleted meanwhile, up to MaxAttempts times.
+func (c *Client) UpdateObject(ctx context.Context, input *UpdateObjectInput) (*UpdateObjectOutput, error) {
+	maxAttempts := input.MaxAttempts
+	if maxAttempts == 0 {
+		maxAttempts = DefaultUpdateMaxAttempts
+	}
+
+	if maxAttempts < 1 {
+		return nil, errors.New("UpdateObject: max attempts must be positive")
+	}
+
+	var lastErr error
+
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		output, err := c.updateObject(ctx, input)
+
+		switch {
+		case err == nil:
+			output.Attempts = attempt
+			return output, nil
+		case errors.Is(err, ErrPreconditionFailed), isNotFound(err):
+			// The object changed or was deleted since it was read, it can
+			// be read again right away.
+		case errors.Is(err, ErrConditionalRequestConflict):
+			// A concurrent write is in progress, give it some time.
+			if err := sleep(ctx, time.Duration(attempt)*conflictRetryDelay); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, err
+		}
+
+		lastErr = err
+	}
+
+	return nil, fmt.Errorf("UpdateObject: %s still changing after %d attempts: %w", input.Key, maxAttempts, lastErr)
+}
+
+// updateObject runs a single read-modify-write cycle.
+func (c *Client) updateObject(ctx context.Context, input *UpdateObjectInput) (*UpdateObjectOutput, error) {
+	current, err := c.GetObject(ctx, &GetObjectInput{Bucket: input.Bucket, Key: input.Key})
+	if isNotFound(err) {
+		current, err = nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := input.Update(current)
+	if err != nil {
+		return nil, err
+	}
+
+	putInput := &PutObjectInput{
+		Bucket:      input.Bucket,
+		Key:         input.Key,
+		Body:        updated.Body,
+		ContentType: updated.ContentType,
+		Metadata:    updated.Metadata,
+		IfNoneMatch: "*",
+	}
+
+	if current != nil {
+		putInput.IfMatch, putInput.IfNoneMatch = current.ETag, ""
+	}
+
+	output, err := c.PutObject(ctx, putInput)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UpdateObjectOutput{
+		ETag:      output.ETag,
+		VersionID: output.VersionID,
+	}, nil
+}
+
+// isNotFound reports whether the request failed because the object does not
+// exist.
+func isNotFound(err error) bool {
+	var apiErr *api.Error
+	return errors.As(err, &apiErr) && apiErr.StatusCode == fasthttp.StatusNotFound
+}
+
+// sleep waits for the delay unless the context is done first.
+func sleep(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,264 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/lvjp/s3hobby/pkg/s3/api"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+// fakeVersionedObject is a single object honoring the conditional writes.
+type fakeVersionedObject struct {
+	// beforePut is called before evaluating the conditions of a write.
+	beforePut func(o *fakeVersionedObject)
+
+	// conflicts is the number of writes answered with a conflict.
+	conflicts int
+
+	content []byte
+	version int
+}
+
+// newConditionalServer returns a server storing the object.
+func newConditionalServer(t *testing.T, object *fakeVersionedObject) *fakeServer {
+	t.Helper()
+
+	server := newFakeServer(t)
+	server.on(fasthttp.MethodGet, "", object.get)
+	server.on(fasthttp.MethodPut, "", object.put)
+
+	return server
+}
+
+func (o *fakeVersionedObject) etag() string {
+	return fmt.Sprintf(`"v%d"`, o.version)
+}
+
+func (o *fakeVersionedObject) get(*fasthttp.Request) string {
+	if o.content == nil {
+		return rawResponse(fasthttp.StatusNotFound, "<Error><Code>NoSuchKey</Code></Error>")
+	}
+
+	return rawResponse(fasthttp.StatusOK, string(o.content), "ETag: "+o.etag())
+}
+
+func (o *fakeVersionedObject) put(req *fasthttp.Request) string {
+	if o.beforePut != nil {
+		o.beforePut(o)
+	}
+
+	if o.conflicts > 0 {
+		o.conflicts--
+		return rawResponse(fasthttp.StatusConflict, "<Error><Code>ConditionalRequestConflict</Code></Error>")
+	}
+
+	ifMatch := string(req.Header.Peek(api.HeaderIfMatch))
+	ifNoneMatch := string(req.Header.Peek(api.HeaderIfNoneMatch))
+
+	if ifMatch != "" && o.content == nil {
+		return rawResponse(fasthttp.StatusNotFound, "<Error><Code>NoSuchKey</Code></Error>")
+	}
+
+	if ifMatch != "" && ifMatch != o.etag() || ifNoneMatch == "*" && o.content != nil {
+		return rawResponse(fasthttp.StatusPreconditionFailed, "<Error><Code>PreconditionFailed</Code></Error>")
+	}
+
+	o.content = bytes.Clone(req.Body())
+	o.version++
+
+	return rawResponse(fasthttp.StatusOK, "", "ETag: "+o.etag())
+}
+
+// concurrentWrite simulates a write racing with the update.
+func concurrentWrite(o *fakeVersionedObject) {
+	o.content = append(o.content, '+')
+	o.version++
+}
+
+func appendLine(current *GetObjectOutput) (*UpdatedContent, error) {
+	var body []byte
+	if current != nil {
+		body = current.Body
+	}
+
+	return &UpdatedContent{Body: append(bytes.Clone(body), "line\n"...), ContentType: "text/plain"}, nil
+}
+
+func TestPutObjectConditions(t *testing.T) {
+	object := &fakeVersionedObject{}
+	server := newConditionalServer(t, object)
+	c := server.client
+
+	input := &PutObjectInput{Bucket: "examplebucket", Key: "lock", Body: []byte("owner"), IfNoneMatch: "*"}
+
+	output, err := c.PutObject(context.Background(), input)
+	require.NoError(t, err)
+	assert.Equal(t, `"v1"`, output.ETag)
+	assert.Equal(t, "*", string(server.httpClient.requests[0].Header.Peek(api.HeaderIfNoneMatch)))
+
+	_, err = c.PutObject(context.Background(), input)
+	require.ErrorIs(t, err, ErrPreconditionFailed)
+
+	_, err = c.PutObject(context.Background(), &PutObjectInput{Bucket: "examplebucket", Key: "lock", IfMatch: `"v0"`})
+	require.ErrorIs(t, err, ErrPreconditionFailed)
+
+	_, err = c.PutObject(context.Background(), &PutObjectInput{Bucket: "examplebucket", Key: "lock", IfMatch: `"v1"`})
+	require.NoError(t, err)
+	assert.Equal(t, `"v1"`, string(server.httpClient.requests[3].Header.Peek(api.HeaderIfMatch)))
+
+	object.conflicts = 1
+	_, err = c.PutObject(context.Background(), &PutObjectInput{Bucket: "examplebucket", Key: "lock", IfMatch: `"v2"`})
+	require.ErrorIs(t, err, ErrConditionalRequestConflict)
+	require.NotErrorIs(t, err, ErrPreconditionFailed)
+}
+
+func TestUpdateObject(t *testing.T) {
+	input := &UpdateObjectInput{Bucket: "examplebucket", Key: "example-object", Update: appendLine}
+
+	t.Run("create", func(t *testing.T) {
+		object := &fakeVersionedObject{}
+		server := newConditionalServer(t, object)
+		c := server.client
+
+		output, err := c.UpdateObject(context.Background(), input)
+		require.NoError(t, err)
+
+		assert.Equal(t, &UpdateObjectOutput{ETag: `"v1"`, Attempts: 1}, output)
+		assert.Equal(t, "line\n", string(object.content))
+	})
+
+	t.Run("update", func(t *testing.T) {
+		object := &fakeVersionedObject{content: []byte("first\n"), version: 1}
+		server := newConditionalServer(t, object)
+		c := server.client
+
+		output, err := c.UpdateObject(context.Background(), input)
+		require.NoError(t, err)
+
+		assert.Equal(t, &UpdateObjectOutput{ETag: `"v2"`, Attempts: 1}, output)
+		assert.Equal(t, "first\nline\n", string(object.content))
+
+		require.Len(t, server.httpClient.requests, 2)
+		assert.Equal(t, `"v1"`, string(server.httpClient.requests[1].Header.Peek(api.HeaderIfMatch)))
+		assert.Equal(t, "text/plain", string(server.httpClient.requests[1].Header.ContentType()))
+	})
+
+	t.Run("concurrent writes", func(t *testing.T) {
+		var puts int
+
+		object := &fakeVersionedObject{content: []byte("first\n"), version: 1}
+		object.beforePut = func(o *fakeVersionedObject) {
+			if puts++; puts <= 2 {
+				concurrentWrite(o)
+			}
+		}
+
+		c := newConditionalServer(t, object).client
+
+		output, err := c.UpdateObject(context.Background(), input)
+		require.NoError(t, err)
+
+		assert.Equal(t, 3, output.Attempts)
+		assert.Equal(t, "first\n++line\n", string(object.content), "the update must apply to the latest content")
+	})
+
+	t.Run("deleted meanwhile", func(t *testing.T) {
+		object := &fakeVersionedObject{content: []byte("first\n"), version: 1}
+		object.beforePut = func(o *fakeVersionedObject) {
+			o.content = nil
+			o.beforePut = nil
+		}
+
+		server := newConditionalServer(t, object)
+
+		output, err := server.client.UpdateObject(context.Background(), input)
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, output.Attempts)
+		assert.Equal(t, "line\n", string(object.content), "the object must be created again")
+
+		puts := server.requests(fasthttp.MethodPut, "")
+		require.Len(t, puts, 2)
+		assert.Equal(t, `"v1"`, string(puts[0].Header.Peek(api.HeaderIfMatch)))
+		assert.Equal(t, "*", string(puts[1].Header.Peek(api.HeaderIfNoneMatch)))
+	})
+
+	t.Run("conflicts", func(t *testing.T) {
+		object := &fakeVersionedObject{conflicts: 1}
+		server := newConditionalServer(t, object)
+		c := server.client
+
+		output, err := c.UpdateObject(context.Background(), input)
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, output.Attempts)
+		assert.Equal(t, "line\n", string(object.content))
+	})
+
+	t.Run("too many attempts", func(t *testing.T) {
+		object := &fakeVersionedObject{beforePut: concurrentWrite}
+		server := newConditionalServer(t, object)
+		c := server.client
+
+		_, err := c.UpdateObject(context.Background(), &UpdateObjectInput{
+			Bucket:      "examplebucket",
+			Key:         "example-object",
+			Update:      appendLine,
+			MaxAttempts: 2,
+		})
+		require.ErrorIs(t, err, ErrPreconditionFailed)
+		require.ErrorContains(t, err, "UpdateObject: example-object still changing after 2 attempts")
+		assert.Len(t, server.requests(fasthttp.MethodPut, ""), 2)
+	})
+
+	t.Run("invalid max attempts", func(t *testing.T) {
+		server := newConditionalServer(t, &fakeVersionedObject{})
+
+		_, err := server.client.UpdateObject(context.Background(), &UpdateObjectInput{
+			Bucket:      "examplebucket",
+			Key:         "example-object",
+			Update:      appendLine,
+			MaxAttempts: -1,
+		})
+		require.EqualError(t, err, "UpdateObject: max attempts must be positive")
+		assert.Empty(t, server.httpClient.requests)
+	})
+
+	t.Run("update failure", func(t *testing.T) {
+		object := &fakeVersionedObject{}
+		server := newConditionalServer(t, object)
+		c := server.client
+
+		updateErr := errors.New("invalid content")
+
+		_, err := c.UpdateObject(context.Background(), &UpdateObjectInput{
+			Bucket: "examplebucket",
+			Key:    "example-object",
+			Update: func(*GetObjectOutput) (*UpdatedContent, error) {
+				return nil, updateErr
+			},
+		})
+		require.Equal(t, updateErr, err)
+		assert.Empty(t, server.requests(fasthttp.MethodPut, ""))
+	})
+
+	t.Run("canceled while waiting", func(t *testing.T) {
+		object := &fakeVersionedObject{conflicts: 1}
+		server := newConditionalServer(t, object)
+		c := server.client
+
+		ctx, cancel := context.WithCancel(context.Background())
+		object.beforePut = func(*fakeVersionedObject) { cancel() }
+
+		_, err := c.UpdateObject(ctx, input)
+		require.ErrorIs(t, err, context.Canceled)
+		assert.Len(t, server.requests(fasthttp.MethodPut, ""), 1)
+	})
+}
@@ -0,0 +1,85 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/lvjp/s3hobby/pkg/s3/api"
+
+	"github.com/valyala/fasthttp"
+)
+
+// MaxPartNumber is the highest part number of a multipart upload.
+const MaxPartNumber = 10000
+
+type UploadPartInput struct {
+	Bucket   string
+	Key      string
+	UploadID string
+
+	// PartNumber is between 1 and MaxPartNumber.
+	PartNumber int
+	Body       []byte
+
+	// ChecksumAlgorithm computes the matching checksum of Body unless it is
+	// already provided by Checksums.
+	ChecksumAlgorithm ChecksumAlgorithm
+	Checksums         Checksums
+}
+
+type UploadPartOutput struct {
+	PartNumber int
+	ETag       string
+	Checksums  Checksums
+}
+
+// CompletedPart returns the part as expected by CompleteMultipartUpload.
+func (o *UploadPartOutput) CompletedPart() CompletedPart {
+	return CompletedPart{
+		PartNumber: o.PartNumber,
+		ETag:       o.ETag,
+		Checksums:  o.Checksums,
+	}
+}
+
+func (c *Client) UploadPart(ctx context.Context, input *UploadPartInput) (*UploadPartOutput, error) {
+	if input.PartNumber < 1 || input.PartNumber > MaxPartNumber {
+		return nil, errors.New("UploadPart: part number must be between 1 and 10000")
+	}
+
+	checksums := input.Checksums
+	if err := checksums.compute(input.ChecksumAlgorithm, input.Body); err != nil {
+		return nil, err
+	}
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(resp)
+
+	if err := c.setRequestURI(req, input.Bucket, input.Key); err != nil {
+		return nil, err
+	}
+
+	req.Header.SetMethod(fasthttp.MethodPut)
+
+	args := req.URI().QueryArgs()
+	args.Add("partNumber", strconv.Itoa(input.PartNumber))
+	args.Add("uploadId", input.UploadID)
+
+	checksums.setHeaders(&req.Header)
+	req.SetBodyRaw(input.Body)
+
+	if err := c.doBucket(ctx, input.Bucket, req, resp); err != nil {
+		return nil, err
+	}
+
+	output := &UploadPartOutput{
+		PartNumber: input.PartNumber,
+		ETag:       string(resp.Header.Peek(api.HeaderETag)),
+		Checksums:  checksumsFromHeaders(&resp.Header).orSent(checksums),
+	}
+
+	return output, nil
+}
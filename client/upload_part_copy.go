@@ -0,0 +1,93 @@
+package client
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/lvjp/s3hobby/pkg/s3/api"
+
+	"github.com/valyala/fasthttp"
+)
+
+type UploadPartCopyInput struct {
+	Bucket   string
+	Key      string
+	UploadID string
+
+	// PartNumber is between 1 and MaxPartNumber.
+	PartNumber int
+	Source     CopySource
+
+	// Range is the copied range of the source, like "bytes=0-1023". The
+	// whole source is copied when it is empty.
+	Range string
+}
+
+type UploadPartCopyOutput struct {
+	PartNumber      int
+	ETag            string
+	LastModified    time.Time
+	SourceVersionID string
+	Checksums       Checksums
+}
+
+// CompletedPart returns the part as expected by CompleteMultipartUpload.
+func (o *UploadPartCopyOutput) CompletedPart() CompletedPart {
+	return CompletedPart{
+		PartNumber: o.PartNumber,
+		ETag:       o.ETag,
+		Checksums:  o.Checksums,
+	}
+}
+
+// UploadPartCopy uploads a part from a range of an existing object. S3 may
+// report a failure after having sent a 200 OK status, it is returned as
+// *api.Error.
+func (c *Client) UploadPartCopy(ctx context.Context, input *UploadPartCopyInput) (*UploadPartCopyOutput, error) {
+	if input.PartNumber < 1 || input.PartNumber > MaxPartNumber {
+		return nil, errors.New("UploadPartCopy: part number must be between 1 and 10000")
+	}
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(resp)
+
+	if err := c.setRequestURI(req, input.Bucket, input.Key); err != nil {
+		return nil, err
+	}
+
+	req.Header.SetMethod(fasthttp.MethodPut)
+
+	args := req.URI().QueryArgs()
+	args.Add("partNumber", strconv.Itoa(input.PartNumber))
+	args.Add("uploadId", input.UploadID)
+
+	input.Source.setHeaders(&req.Header)
+	setHeader(&req.Header, api.HeaderXAmzCopySourceRange, input.Range)
+
+	if err := c.doBucket(ctx, input.Bucket, req, resp); err != nil {
+		return nil, conditionalError(err)
+	}
+
+	if err := checkErrorBody(resp); err != nil {
+		return nil, conditionalError(err)
+	}
+
+	var result api.CopyPartResult
+	if err := xml.Unmarshal(resp.Body(), &result); err != nil {
+		return nil, fmt.Errorf("UploadPartCopy: cannot parse response: %w", err)
+	}
+
+	return &UploadPartCopyOutput{
+		PartNumber:      input.PartNumber,
+		ETag:            result.ETag,
+		LastModified:    result.LastModified,
+		SourceVersionID: string(resp.Header.Peek(api.HeaderXAmzCopySourceVersionID)),
+		Checksums:       checksumsFromAPI(result.Checksums),
+	}, nil
+}
@@ -0,0 +1,525 @@
+package client
+
+import (
+	"cmp"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"slices"
+	"sync"
+)
+
+const (
+	// MinPartSize is the smallest size of a part, except for the last one.
+	MinPartSize int64 = 5 << 20
+
+	// MaxPartSize is the largest size of a part.
+	MaxPartSize int64 = 5 << 30
+
+	// MaxObjectSize is the largest size of an object.
+	MaxObjectSize int64 = 5 << 40
+
+	DefaultUploadPartSize    int64 = 8 << 20
+	DefaultUploadConcurrency       = 5
+)
+
+// partGrowthInterval is the number of parts after which the part size doubles
+// when the body size is unknown. Starting from MinPartSize, MaxPartNumber
+// parts then hold more than MaxObjectSize.
+const partGrowthInterval = 1000
+
+type UploaderOptions struct {
+	// PartSize defaults to DefaultUploadPartSize. It is raised when needed to
+	// stay within MaxPartNumber parts.
+	PartSize int64
+
+	// Concurrency is the number of parts uploaded in parallel, it defaults to
+	// DefaultUploadConcurrency. At most Concurrency parts are held in memory.
+	Concurrency int
+}
+
+// Uploader uploads objects of any size, using a multipart upload for bodies
+// larger than a part. It is safe for concurrent use.
+type Uploader struct {
+	client  *Client
+	options UploaderOptions
+
+	// buffers holds *[]byte of at least options.PartSize bytes.
+	buffers sync.Pool
+}
+
+func NewUploader(client *Client, options UploaderOptions) (*Uploader, error) {
+	if options.PartSize == 0 {
+		options.PartSize = DefaultUploadPartSize
+	}
+
+	if options.PartSize < MinPartSize || options.PartSize > MaxPartSize {
+		return nil, fmt.Errorf("Uploader: part size must be between %d and %d bytes", MinPartSize, MaxPartSize)
+	}
+
+	if options.Concurrency == 0 {
+		options.Concurrency = DefaultUploadConcurrency
+	}
+
+	if options.Concurrency < 1 {
+		return nil, errors.New("Uploader: concurrency must be positive")
+	}
+
+	return &Uploader{
+		client:  client,
+		options: options,
+	}, nil
+}
+
+type UploadInput struct {
+	Bucket string
+	Key    string
+
+	// Body is read until io.EOF, its size is known when it implements
+	// io.Seeker or a Len() int method like bytes.Reader.
+	Body io.Reader
+
+	ContentType  string
+	Metadata     map[string]string
+	StorageClass string
+	Tagging      TagSet
+
+	// ChecksumAlgorithm defaults to ChecksumAlgorithmCRC32.
+	ChecksumAlgorithm ChecksumAlgorithm
+
+	// ChecksumType defaults to ChecksumTypeFullObject for the CRC algorithms
+	// and ChecksumTypeComposite for the others.
+	ChecksumType ChecksumType
+}
+
+type UploadOutput struct {
+	ETag      string
+	VersionID string
+
+	// UploadID is empty when the object was sent with PutObject.
+	UploadID  string
+	PartCount int
+
+	// Checksums holds the object checksum, computed by the client and checked
+	// against the one of S3.
+	Checksums    Checksums
+	ChecksumType ChecksumType
+}
+
+// MultipartUploadError reports a failed multipart upload. The upload has been
+// aborted unless AbortErr is set, its parts are then still billed.
+type MultipartUploadError struct {
+	// Operation is the helper running the upload, like "Uploader" or "Copier".
+	Operation string
+
+	UploadID string
+	Err      error
+	AbortErr error
+}
+
+func (e *MultipartUploadError) Error() string {
+	if e.AbortErr != nil {
+		return fmt.Sprintf("%s: upload %s failed: %v (abort failed: %v)", e.Operation, e.UploadID, e.Err, e.AbortErr)
+	}
+
+	return fmt.Sprintf("%s: upload %s failed: %v", e.Operation, e.UploadID, e.Err)
+}
+
+func (e *MultipartUploadError) Unwrap() []error {
+	return []error{e.Err, e.AbortErr}
+}
+
+// Upload reads the body and uploads it. Bodies fitting in a part are sent
+// with PutObject, the others with a multipart upload whose parts are sent
+// concurrently. On failure or context cancellation, the multipart upload is
+// aborted and a *MultipartUploadError is returned.
+func (u *Uploader) Upload(ctx context.Context, input *UploadInput) (*UploadOutput, error) {
+	algorithm, checksumType, err := checksumSettings(input.ChecksumAlgorithm, input.ChecksumType)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := input.Tagging.Validate(); err != nil {
+		return nil, fmt.Errorf("Uploader: %w", err)
+	}
+
+	sizes, err := u.partSizes(input.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	first, err := u.readPart(input.Body, sizes.of(1))
+	if err != nil {
+		return nil, err
+	}
+
+	if first.last || sizes.singlePart() {
+		defer u.buffers.Put(first.buffer)
+
+		return u.putObject(ctx, input, first.body(), algorithm)
+	}
+
+	created, err := u.client.CreateMultipartUpload(ctx, &CreateMultipartUploadInput{
+		Bucket:            input.Bucket,
+		Key:               input.Key,
+		ContentType:       input.ContentType,
+		Metadata:          input.Metadata,
+		StorageClass:      input.StorageClass,
+		Tagging:           input.Tagging,
+		ChecksumAlgorithm: algorithm,
+		ChecksumType:      checksumType,
+	})
+	if err != nil {
+		u.buffers.Put(first.buffer)
+		return nil, err
+	}
+
+	upload := &multipartUpload{
+		uploader:     u,
+		input:        input,
+		uploadID:     created.UploadID,
+		algorithm:    algorithm,
+		checksumType: checksumType,
+		sizes:        sizes,
+	}
+
+	output, err := upload.run(ctx, first)
+	if err != nil {
+		return nil, u.client.abortMultipartUpload(ctx, "Uploader", input.Bucket, input.Key, created.UploadID, err)
+	}
+
+	// The object now exists, a checksum mismatch cannot be aborted.
+	if checksumType == ChecksumTypeComposite {
+		if err := checkCompositeChecksum(algorithm, upload.parts, output); err != nil {
+			return nil, err
+		}
+	}
+
+	return output, nil
+}
+
+// checksumSettings applies the defaults of the checksum settings of uploads.
+func checksumSettings(algorithm ChecksumAlgorithm, checksumType ChecksumType) (ChecksumAlgorithm, ChecksumType, error) {
+	if algorithm == "" {
+		algorithm = ChecksumAlgorithmCRC32
+	}
+
+	if checksumType == "" {
+		checksumType = algorithm.defaultChecksumType()
+	}
+
+	if checksumType == ChecksumTypeFullObject && algorithm.defaultChecksumType() != ChecksumTypeFullObject {
+		return "", "", fmt.Errorf("Uploader: %s does not support the %s checksum type", algorithm, checksumType)
+	}
+
+	return algorithm, checksumType, nil
+}
+
+func (u *Uploader) putObject(ctx context.Context, input *UploadInput, body []byte, algorithm ChecksumAlgorithm) (*UploadOutput, error) {
+	output, err := u.client.PutObject(ctx, &PutObjectInput{
+		Bucket:            input.Bucket,
+		Key:               input.Key,
+		Body:              body,
+		ContentType:       input.ContentType,
+		Metadata:          input.Metadata,
+		StorageClass:      input.StorageClass,
+		Tagging:           input.Tagging,
+		ChecksumAlgorithm: algorithm,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &UploadOutput{
+		ETag:         output.ETag,
+		VersionID:    output.VersionID,
+		PartCount:    1,
+		Checksums:    output.Checksums,
+		ChecksumType: ChecksumTypeFullObject,
+	}, nil
+}
+
+// abortMultipartUpload aborts the upload of a failed operation even when the
+// context is canceled, so that its parts are not left behind.
+func (c *Client) abortMultipartUpload(ctx context.Context, operation, bucket, key, uploadID string, err error) error {
+	_, abortErr := c.AbortMultipartUpload(context.WithoutCancel(ctx), &AbortMultipartUploadInput{
+		Bucket:   bucket,
+		Key:      key,
+		UploadID: uploadID,
+	})
+
+	return &MultipartUploadError{
+		Operation: operation,
+		UploadID:  uploadID,
+		Err:       err,
+		AbortErr:  abortErr,
+	}
+}
+
+// partSizes returns the part sizes fitting the body within MaxPartNumber parts.
+func (u *Uploader) partSizes(body io.Reader) (partSizes, error) {
+	size, known, err := bodySize(body)
+	if err != nil {
+		return partSizes{}, err
+	}
+
+	if !known {
+		return partSizes{base: u.options.PartSize, grow: true}, nil
+	}
+
+	if size > MaxObjectSize {
+		return partSizes{}, fmt.Errorf("Uploader: body of %d bytes exceeds the largest object size", size)
+	}
+
+	return partSizes{base: max(u.options.PartSize, (size+MaxPartNumber-1)/MaxPartNumber), size: size}, nil
+}
+
+// bodySize returns the remaining size of the body, when it can be known
+// without reading it.
+func bodySize(body io.Reader) (int64, bool, error) {
+	switch body := body.(type) {
+	case interface{ Len() int }:
+		return int64(body.Len()), true, nil
+	case io.Seeker:
+		current, err := body.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return 0, false, fmt.Errorf("Uploader: cannot seek body: %w", err)
+		}
+
+		end, err := body.Seek(0, io.SeekEnd)
+		if err != nil {
+			return 0, false, fmt.Errorf("Uploader: cannot seek body: %w", err)
+		}
+
+		if _, err := body.Seek(current, io.SeekStart); err != nil {
+			return 0, false, fmt.Errorf("Uploader: cannot seek body: %w", err)
+		}
+
+		return end - current, true, nil
+	default:
+		return 0, false, nil
+	}
+}
+
+// partSizes tells the size of every part. When the body size is unknown, the
+// size doubles every partGrowthInterval parts, up to MaxPartSize.
+type partSizes struct {
+	base int64
+	grow bool
+
+	// size is the body size, when it is known.
+	size int64
+}
+
+// singlePart reports whether the body is known to fit in the first part.
+func (s partSizes) singlePart() bool {
+	return !s.grow && s.size <= s.base
+}
+
+func (s partSizes) of(partNumber int) int64 {
+	if !s.grow {
+		return s.base
+	}
+
+	return min(s.base<<((partNumber-1)/partGrowthInterval), MaxPartSize)
+}
+
+type bufferedPart struct {
+	buffer *[]byte
+	size   int
+
+	// last is set when the body has been fully read.
+	last bool
+}
+
+func (p bufferedPart) body() []byte {
+	return (*p.buffer)[:p.size]
+}
+
+// readPart reads the next part, which is empty when the body has been fully
+// read by the previous one.
+func (u *Uploader) readPart(body io.Reader, size int64) (bufferedPart, error) {
+	buffer := u.getBuffer(size)
+
+	n, err := io.ReadFull(body, *buffer)
+	switch {
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return bufferedPart{buffer: buffer, size: n, last: true}, nil
+	case err != nil:
+		u.buffers.Put(buffer)
+		return bufferedPart{}, fmt.Errorf("Uploader: cannot read body: %w", err)
+	default:
+		return bufferedPart{buffer: buffer, size: n}, nil
+	}
+}
+
+func (u *Uploader) getBuffer(size int64) *[]byte {
+	// Smaller buffers come from parts of a previous size, they are dropped.
+	if buffer, ok := u.buffers.Get().(*[]byte); ok && int64(cap(*buffer)) >= size {
+		*buffer = (*buffer)[:size]
+		return buffer
+	}
+
+	buffer := make([]byte, size)
+
+	return &buffer
+}
+
+// multipartUpload holds the state of an Upload using a multipart upload.
+type multipartUpload struct {
+	uploader *Uploader
+	input    *UploadInput
+	uploadID string
+
+	algorithm    ChecksumAlgorithm
+	checksumType ChecksumType
+	sizes        partSizes
+
+	mu    sync.Mutex
+	parts []CompletedPart
+}
+
+func (m *multipartUpload) run(ctx context.Context, first bufferedPart) (*UploadOutput, error) {
+	fullObject, err := m.uploadParts(ctx, first)
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(m.parts, func(a, b CompletedPart) int {
+		return cmp.Compare(a.PartNumber, b.PartNumber)
+	})
+
+	var checksums Checksums
+
+	if fullObject != nil {
+		*checksums.field(m.algorithm) = base64.StdEncoding.EncodeToString(fullObject.Sum(nil))
+	}
+
+	output, err := m.uploader.client.CompleteMultipartUpload(ctx, &CompleteMultipartUploadInput{
+		Bucket:       m.input.Bucket,
+		Key:          m.input.Key,
+		UploadID:     m.uploadID,
+		Parts:        m.parts,
+		Checksums:    checksums,
+		ChecksumType: m.checksumType,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &UploadOutput{
+		ETag:         output.ETag,
+		VersionID:    output.VersionID,
+		UploadID:     m.uploadID,
+		PartCount:    len(m.parts),
+		Checksums:    output.Checksums,
+		ChecksumType: m.checksumType,
+	}, nil
+}
+
+// checkCompositeChecksum checks the object checksum computed by S3 from the
+// part checksums, parts being sorted. Full object checksums are checked by S3
+// itself.
+func checkCompositeChecksum(algorithm ChecksumAlgorithm, parts []CompletedPart, output *UploadOutput) error {
+	expected, err := compositeChecksum(algorithm, parts)
+	if err != nil {
+		return err
+	}
+
+	if actual := output.Checksums.Get(algorithm); actual != "" && actual != expected {
+		return fmt.Errorf("%w: %s of upload %s is %q instead of %q", ErrChecksumMismatch, algorithm, output.UploadID, actual, expected)
+	}
+
+	*output.Checksums.field(algorithm) = expected
+
+	return nil
+}
+
+// uploadParts uploads the parts read from the body, starting with the first
+// one already read. Reading the body is sequential, so the hash of the whole
+// object is returned for the full object checksum type.
+func (m *multipartUpload) uploadParts(ctx context.Context, first bufferedPart) (hash.Hash, error) {
+	var fullObject hash.Hash
+	if m.checksumType == ChecksumTypeFullObject {
+		fullObject, _ = m.algorithm.newHash() // The algorithm has been checked by Upload.
+	}
+
+	group := newTaskGroup(ctx, m.uploader.options.Concurrency)
+
+	// A slot is taken before reading a part, which bounds the memory usage.
+	if !group.Acquire() {
+		m.uploader.buffers.Put(first.buffer)
+		return nil, group.Wait()
+	}
+
+	part := first
+
+	for partNumber := 1; ; partNumber++ {
+		if part.size == 0 {
+			m.uploader.buffers.Put(part.buffer)
+			group.Release()
+
+			break
+		}
+
+		if fullObject != nil {
+			fullObject.Write(part.body())
+		}
+
+		current := part
+		group.Go(func(ctx context.Context) error {
+			defer m.uploader.buffers.Put(current.buffer)
+
+			return m.uploadPart(ctx, partNumber, current.body())
+		})
+
+		if part.last || !group.Acquire() {
+			break
+		}
+
+		next, err := m.uploader.readPart(m.input.Body, m.sizes.of(partNumber+1))
+		if err == nil && next.size > 0 && partNumber == MaxPartNumber {
+			m.uploader.buffers.Put(next.buffer)
+			err = fmt.Errorf("Uploader: body does not fit in %d parts", MaxPartNumber)
+		}
+
+		if err != nil {
+			group.Fail(err)
+			group.Release()
+
+			break
+		}
+
+		part = next
+	}
+
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+
+	return fullObject, nil
+}
+
+func (m *multipartUpload) uploadPart(ctx context.Context, partNumber int, body []byte) error {
+	output, err := m.uploader.client.UploadPart(ctx, &UploadPartInput{
+		Bucket:            m.input.Bucket,
+		Key:               m.input.Key,
+		UploadID:          m.uploadID,
+		PartNumber:        partNumber,
+		Body:              body,
+		ChecksumAlgorithm: m.algorithm,
+	})
+	if err != nil {
+		return fmt.Errorf("part %d: %w", partNumber, err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.parts = append(m.parts, output.CompletedPart())
+
+	return nil
+}
@@ -0,0 +1,387 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"testing"
+	"testing/iotest"
+
+	"github.com/lvjp/s3hobby/pkg/s3/api"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+// fakeMultipartStore stores the objects and parts sent by the uploads.
+type fakeMultipartStore struct {
+	// failPart answers the upload of this part with an internal error.
+	failPart int
+
+	// onPart is called before storing a part.
+	onPart func(partNumber int)
+
+	// completeChecksum is returned as the checksum of completed uploads.
+	completeChecksum string
+
+	// noSuchUpload answers ListParts as if the upload was aborted.
+	noSuchUpload bool
+
+	objects       map[string][]byte
+	parts         map[int][]byte
+	partChecksums map[int]string
+}
+
+// newMultipartServer returns a server storing the uploads in the store.
+func newMultipartServer(t *testing.T, store *fakeMultipartStore) *fakeServer {
+	t.Helper()
+
+	server := newFakeServer(t)
+	server.on(fasthttp.MethodPost, "uploads", store.createMultipartUpload)
+	server.on(fasthttp.MethodPut, "partNumber", store.uploadPart)
+	server.on(fasthttp.MethodPut, "", store.putObject)
+	server.on(fasthttp.MethodPost, "uploadId", store.completeMultipartUpload)
+	server.on(fasthttp.MethodGet, "uploadId", store.listParts)
+	server.on(fasthttp.MethodDelete, "uploadId", func(*fasthttp.Request) string {
+		return rawResponse(fasthttp.StatusNoContent, "")
+	})
+
+	return server
+}
+
+func (s *fakeMultipartStore) createMultipartUpload(*fasthttp.Request) string {
+	s.parts = make(map[int][]byte)
+	s.partChecksums = make(map[int]string)
+
+	return rawResponse(fasthttp.StatusOK, "<InitiateMultipartUploadResult><UploadId>UPLOAD</UploadId></InitiateMultipartUploadResult>")
+}
+
+func (s *fakeMultipartStore) uploadPart(req *fasthttp.Request) string {
+	partNumber, _ := strconv.Atoi(string(req.URI().QueryArgs().Peek("partNumber")))
+	if s.onPart != nil {
+		s.onPart(partNumber)
+	}
+
+	if partNumber == s.failPart {
+		return rawResponse(fasthttp.StatusInternalServerError, "<Error><Code>InternalError</Code></Error>")
+	}
+
+	s.parts[partNumber] = bytes.Clone(req.Body())
+	s.partChecksums[partNumber] = string(req.Header.Peek(api.HeaderXAmzChecksumCrc32))
+
+	return rawResponse(fasthttp.StatusOK, "", fmt.Sprintf(`ETag: "etag%d"`, partNumber))
+}
+
+func (s *fakeMultipartStore) putObject(req *fasthttp.Request) string {
+	if s.objects == nil {
+		s.objects = make(map[string][]byte)
+	}
+
+	s.objects[string(req.URI().Path())] = bytes.Clone(req.Body())
+
+	return rawResponse(fasthttp.StatusOK, "", `ETag: "object"`)
+}
+
+func (s *fakeMultipartStore) completeMultipartUpload(*fasthttp.Request) string {
+	return rawResponse(fasthttp.StatusOK, "<CompleteMultipartUploadResult><ETag>&quot;multipart-3&quot;</ETag>"+
+		s.completeChecksum+"</CompleteMultipartUploadResult>")
+}
+
+func (s *fakeMultipartStore) listParts(*fasthttp.Request) string {
+	if s.noSuchUpload {
+		return rawResponse(fasthttp.StatusNotFound, "<Error><Code>NoSuchUpload</Code></Error>")
+	}
+
+	body := "<ListPartsResult>"
+	for partNumber, part := range s.parts {
+		body += fmt.Sprintf("<Part><PartNumber>%d</PartNumber><ETag>&quot;etag%d&quot;</ETag><Size>%d</Size><ChecksumCRC32>%s</ChecksumCRC32></Part>",
+			partNumber, partNumber, len(part), s.partChecksums[partNumber])
+	}
+
+	return rawResponse(fasthttp.StatusOK, body+"</ListPartsResult>")
+}
+
+// object concatenates the uploaded parts.
+func (s *fakeMultipartStore) object() []byte {
+	var ret []byte
+	for partNumber := 1; partNumber <= len(s.parts); partNumber++ {
+		ret = append(ret, s.parts[partNumber]...)
+	}
+
+	return ret
+}
+
+// unsizedReader hides the size of the wrapped reader.
+type unsizedReader struct {
+	io.Reader
+}
+
+var testUploaderOptions = UploaderOptions{PartSize: MinPartSize, Concurrency: 2}
+
+func TestUploaderPutObject(t *testing.T) {
+	store := &fakeMultipartStore{}
+	server := newMultipartServer(t, store)
+	uploader := newTestHelper(t, server, NewUploader, testUploaderOptions)
+
+	output, err := uploader.Upload(context.Background(), &UploadInput{
+		Bucket: "examplebucket",
+		Key:    "example-object",
+		Body:   unsizedReader{bytes.NewReader([]byte("123456789"))},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, &UploadOutput{
+		ETag:         `"object"`,
+		PartCount:    1,
+		Checksums:    Checksums{CRC32: "y/Q5Jg=="},
+		ChecksumType: ChecksumTypeFullObject,
+	}, output)
+
+	require.Len(t, server.httpClient.requests, 1)
+	assert.Equal(t, "y/Q5Jg==", string(server.httpClient.requests[0].Header.Peek(api.HeaderXAmzChecksumCrc32)))
+	assert.Equal(t, []byte("123456789"), store.objects["/example-object"])
+}
+
+func TestUploaderPartSizeBoundary(t *testing.T) {
+	body := bytes.Repeat([]byte("0123456789abcdef"), int(MinPartSize)/16)
+
+	testCases := map[string]struct {
+		body io.Reader
+
+		expectedRequests int
+	}{
+		"known size":   {body: bytes.NewReader(body), expectedRequests: 1},
+		"seekable":     {body: io.NewSectionReader(bytes.NewReader(body), 0, MinPartSize), expectedRequests: 1},
+		"unknown size": {body: unsizedReader{bytes.NewReader(body)}, expectedRequests: 3},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			store := &fakeMultipartStore{}
+			server := newMultipartServer(t, store)
+			uploader := newTestHelper(t, server, NewUploader, testUploaderOptions)
+
+			_, err := uploader.Upload(context.Background(), &UploadInput{
+				Bucket: "examplebucket",
+				Key:    "example-object",
+				Body:   tc.body,
+			})
+			require.NoError(t, err)
+
+			assert.Len(t, server.httpClient.requests, tc.expectedRequests)
+		})
+	}
+}
+
+func TestUploaderMultipart(t *testing.T) {
+	body := bytes.Repeat([]byte("0123456789abcdef"), int(2*MinPartSize+MinPartSize/2)/16)
+
+	fullObject, err := computeChecksum(ChecksumAlgorithmCRC32, body)
+	require.NoError(t, err)
+
+	testCases := map[string]io.Reader{
+		"known size":   bytes.NewReader(body),
+		"unknown size": unsizedReader{bytes.NewReader(body)},
+	}
+
+	for name, reader := range testCases {
+		t.Run(name, func(t *testing.T) {
+			store := &fakeMultipartStore{}
+			server := newMultipartServer(t, store)
+			uploader := newTestHelper(t, server, NewUploader, testUploaderOptions)
+
+			output, err := uploader.Upload(context.Background(), &UploadInput{
+				Bucket: "examplebucket",
+				Key:    "example-object",
+				Body:   reader,
+			})
+			require.NoError(t, err)
+
+			assert.Equal(t, &UploadOutput{
+				ETag:         `"multipart-3"`,
+				UploadID:     "UPLOAD",
+				PartCount:    3,
+				Checksums:    Checksums{CRC32: fullObject},
+				ChecksumType: ChecksumTypeFullObject,
+			}, output)
+
+			assert.True(t, bytes.Equal(body, store.object()), "uploaded object differs from the body")
+			assert.Len(t, server.httpClient.requests, 5)
+
+			create, complete := server.httpClient.requests[0], server.httpClient.requests[4]
+			assert.Equal(t, "CRC32", string(create.Header.Peek(api.HeaderXAmzChecksumAlgorithm)))
+			assert.Equal(t, "FULL_OBJECT", string(create.Header.Peek(api.HeaderXAmzChecksumType)))
+			assert.Equal(t, fullObject, string(complete.Header.Peek(api.HeaderXAmzChecksumCrc32)))
+			assert.Contains(t, string(complete.Body()), "<Part><PartNumber>1</PartNumber><ETag>&#34;etag1&#34;</ETag>")
+			assert.Empty(t, server.requests(fasthttp.MethodDelete, "uploadId"))
+		})
+	}
+}
+
+func TestUploaderCompositeChecksum(t *testing.T) {
+	body := bytes.Repeat([]byte("0123456789abcdef"), int(2*MinPartSize+MinPartSize/2)/16)
+
+	var parts []CompletedPart
+	for partNumber, chunk := range [][]byte{body[:MinPartSize], body[MinPartSize : 2*MinPartSize], body[2*MinPartSize:]} {
+		checksum, err := computeChecksum(ChecksumAlgorithmSHA256, chunk)
+		require.NoError(t, err)
+
+		parts = append(parts, CompletedPart{PartNumber: partNumber + 1, Checksums: Checksums{SHA256: checksum}})
+	}
+
+	expected, err := compositeChecksum(ChecksumAlgorithmSHA256, parts)
+	require.NoError(t, err)
+
+	input := &UploadInput{
+		Bucket:            "examplebucket",
+		Key:               "example-object",
+		ChecksumAlgorithm: ChecksumAlgorithmSHA256,
+	}
+
+	t.Run("match", func(t *testing.T) {
+		store := &fakeMultipartStore{completeChecksum: "<ChecksumSHA256>" + expected + "</ChecksumSHA256>"}
+		server := newMultipartServer(t, store)
+		uploader := newTestHelper(t, server, NewUploader, testUploaderOptions)
+
+		input.Body = bytes.NewReader(body)
+		output, err := uploader.Upload(context.Background(), input)
+		require.NoError(t, err)
+
+		assert.Equal(t, ChecksumTypeComposite, output.ChecksumType)
+		assert.Equal(t, Checksums{SHA256: expected}, output.Checksums)
+		assert.Empty(t, server.httpClient.requests[4].Header.Peek(api.HeaderXAmzChecksumSHA256))
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		store := &fakeMultipartStore{completeChecksum: "<ChecksumSHA256>bogus-3</ChecksumSHA256>"}
+		server := newMultipartServer(t, store)
+		uploader := newTestHelper(t, server, NewUploader, testUploaderOptions)
+
+		input.Body = bytes.NewReader(body)
+		_, err := uploader.Upload(context.Background(), input)
+		require.ErrorIs(t, err, ErrChecksumMismatch)
+		assert.Empty(t, server.requests(fasthttp.MethodDelete, "uploadId"), "a completed upload cannot be aborted")
+	})
+}
+
+func TestUploaderAbort(t *testing.T) {
+	body := bytes.Repeat([]byte("0123456789abcdef"), int(4*MinPartSize)/16)
+
+	t.Run("part failure", func(t *testing.T) {
+		store := &fakeMultipartStore{failPart: 2}
+		server := newMultipartServer(t, store)
+		uploader := newTestHelper(t, server, NewUploader, testUploaderOptions)
+
+		_, err := uploader.Upload(context.Background(), &UploadInput{
+			Bucket: "examplebucket",
+			Key:    "example-object",
+			Body:   bytes.NewReader(body),
+		})
+
+		var uploadErr *MultipartUploadError
+		require.ErrorAs(t, err, &uploadErr)
+		assert.Equal(t, "UPLOAD", uploadErr.UploadID)
+		assert.Equal(t, "Uploader", uploadErr.Operation)
+		require.NoError(t, uploadErr.AbortErr)
+
+		var apiErr *api.Error
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, "InternalError", apiErr.Code)
+
+		assert.Len(t, server.requests(fasthttp.MethodDelete, "uploadId"), 1)
+		assert.Empty(t, server.requests(fasthttp.MethodPost, "uploadId"))
+	})
+
+	t.Run("context canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		store := &fakeMultipartStore{
+			onPart: func(partNumber int) {
+				if partNumber == 2 {
+					cancel()
+				}
+			},
+		}
+		server := newMultipartServer(t, store)
+		uploader := newTestHelper(t, server, NewUploader, testUploaderOptions)
+
+		_, err := uploader.Upload(ctx, &UploadInput{
+			Bucket: "examplebucket",
+			Key:    "example-object",
+			Body:   bytes.NewReader(body),
+		})
+
+		var uploadErr *MultipartUploadError
+		require.ErrorAs(t, err, &uploadErr)
+		require.ErrorIs(t, err, context.Canceled)
+
+		assert.Len(t, server.requests(fasthttp.MethodDelete, "uploadId"), 1)
+		assert.Empty(t, server.requests(fasthttp.MethodPost, "uploadId"))
+	})
+
+	t.Run("read failure", func(t *testing.T) {
+		store := &fakeMultipartStore{}
+		server := newMultipartServer(t, store)
+		uploader := newTestHelper(t, server, NewUploader, testUploaderOptions)
+
+		readErr := errors.New("disk failure")
+
+		_, err := uploader.Upload(context.Background(), &UploadInput{
+			Bucket: "examplebucket",
+			Key:    "example-object",
+			Body:   io.MultiReader(bytes.NewReader(body[:MinPartSize+1]), iotest.ErrReader(readErr)),
+		})
+		require.ErrorIs(t, err, readErr)
+		assert.Len(t, server.requests(fasthttp.MethodDelete, "uploadId"), 1)
+	})
+}
+
+func TestPartSizes(t *testing.T) {
+	uploader, err := NewUploader(nil, UploaderOptions{})
+	require.NoError(t, err)
+
+	t.Run("unknown size", func(t *testing.T) {
+		sizes, err := uploader.partSizes(unsizedReader{})
+		require.NoError(t, err)
+
+		assert.Equal(t, DefaultUploadPartSize, sizes.of(1))
+		assert.Equal(t, DefaultUploadPartSize, sizes.of(partGrowthInterval))
+		assert.Equal(t, 2*DefaultUploadPartSize, sizes.of(partGrowthInterval+1))
+		assert.Equal(t, DefaultUploadPartSize<<9, sizes.of(MaxPartNumber))
+
+		var total int64
+		for partNumber := 1; partNumber <= MaxPartNumber; partNumber++ {
+			total += sizes.of(partNumber)
+		}
+
+		assert.Greater(t, total, MaxObjectSize, "MaxPartNumber parts must hold the largest object")
+	})
+
+	t.Run("known size", func(t *testing.T) {
+		sizes, err := uploader.partSizes(bytes.NewReader(make([]byte, 10)))
+		require.NoError(t, err)
+		assert.Equal(t, partSizes{base: DefaultUploadPartSize, size: 10}, sizes)
+		assert.True(t, sizes.singlePart())
+
+		sizes, err = uploader.partSizes(io.NewSectionReader(nil, 0, 1<<40))
+		require.NoError(t, err)
+		assert.Equal(t, partSizes{base: (1<<40 + MaxPartNumber - 1) / MaxPartNumber, size: 1 << 40}, sizes)
+		assert.False(t, sizes.singlePart())
+
+		_, err = uploader.partSizes(io.NewSectionReader(nil, 0, 6<<40))
+		require.EqualError(t, err, "Uploader: body of 6597069766656 bytes exceeds the largest object size")
+	})
+}
+
+func TestNewUploader(t *testing.T) {
+	_, err := NewUploader(nil, UploaderOptions{PartSize: MinPartSize - 1})
+	require.EqualError(t, err, "Uploader: part size must be between 5242880 and 5368709120 bytes")
+
+	_, err = NewUploader(nil, UploaderOptions{Concurrency: -1})
+	require.EqualError(t, err, "Uploader: concurrency must be positive")
+}
@@ -0,0 +1,29 @@
+package api
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+type CreateBucketConfiguration struct {
+	XMLName xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ CreateBucketConfiguration"`
+
+	LocationConstraint string `xml:"LocationConstraint,omitempty"`
+}
+
+type ListAllMyBucketsResult struct {
+	Owner             *Owner   `xml:"Owner"`
+	Buckets           []Bucket `xml:"Buckets>Bucket"`
+	ContinuationToken string   `xml:"ContinuationToken"`
+	Prefix            string   `xml:"Prefix"`
+}
+
+type Bucket struct {
+	Name         string    `xml:"Name"`
+	CreationDate time.Time `xml:"CreationDate"`
+	BucketRegion string    `xml:"BucketRegion"`
+}
+
+type LocationConstraint struct {
+	Value string `xml:",chardata"`
+}
@@ -0,0 +1,16 @@
+package api
+
+import "time"
+
+type CopyObjectResult struct {
+	ETag         string    `xml:"ETag"`
+	LastModified time.Time `xml:"LastModified"`
+	ChecksumType string    `xml:"ChecksumType"`
+	Checksums
+}
+
+type CopyPartResult struct {
+	ETag         string    `xml:"ETag"`
+	LastModified time.Time `xml:"LastModified"`
+	Checksums
+}
@@ -0,0 +1,33 @@
+package api
+
+import "encoding/xml"
+
+type Delete struct {
+	XMLName xml.Name           `xml:"http://s3.amazonaws.com/doc/2006-03-01/ Delete"`
+	Objects []ObjectIdentifier `xml:"Object"`
+	Quiet   bool               `xml:"Quiet,omitempty"`
+}
+
+type ObjectIdentifier struct {
+	Key       string `xml:"Key"`
+	VersionID string `xml:"VersionId,omitempty"`
+}
+
+type DeleteResult struct {
+	Deleted []DeletedObject `xml:"Deleted"`
+	Errors  []DeleteError   `xml:"Error"`
+}
+
+type DeletedObject struct {
+	Key                   string `xml:"Key"`
+	VersionID             string `xml:"VersionId"`
+	DeleteMarker          bool   `xml:"DeleteMarker"`
+	DeleteMarkerVersionID string `xml:"DeleteMarkerVersionId"`
+}
+
+type DeleteError struct {
+	Key       string `xml:"Key"`
+	VersionID string `xml:"VersionId"`
+	Code      string `xml:"Code"`
+	Message   string `xml:"Message"`
+}
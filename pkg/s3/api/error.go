@@ -0,0 +1,40 @@
+package api
+
+import (
+	"fmt"
+)
+
+const ErrorCodeConditionalRequestConflict = "ConditionalRequestConflict"
+const ErrorCodePreconditionFailed = "PreconditionFailed"
+const ErrorCodeRequestTimeTooSkewed = "RequestTimeTooSkewed"
+const ErrorCodeSignatureDoesNotMatch = "SignatureDoesNotMatch"
+
+// Error is the XML document returned by S3 when a request fails.
+type Error struct {
+	// StatusCode is the HTTP status of the response, it is not part of the document.
+	StatusCode int `xml:"-"`
+
+	// BucketRegion is the x-amz-bucket-region header of the response, sent
+	// when the bucket is in another region than the request one.
+	BucketRegion string `xml:"-"`
+
+	Code      string `xml:"Code"`
+	Message   string `xml:"Message"`
+	Resource  string `xml:"Resource"`
+	RequestID string `xml:"RequestId"`
+	HostID    string `xml:"HostId"`
+
+	// Details provided along SignatureDoesNotMatch
+	AWSAccessKeyID    string `xml:"AWSAccessKeyId"`
+	SignatureProvided string `xml:"SignatureProvided"`
+	StringToSign      string `xml:"StringToSign"`
+	CanonicalRequest  string `xml:"CanonicalRequest"`
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("s3: %d %s", e.StatusCode, e.Code)
+	}
+
+	return fmt.Sprintf("s3: %d %s: %s", e.StatusCode, e.Code, e.Message)
+}
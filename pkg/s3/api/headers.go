@@ -1,14 +1,62 @@
 package api
 
 const HeaderAuthorization = "authorization"
+const HeaderCacheControl = "cache-control"
+const HeaderContentDisposition = "content-disposition"
 const HeaderContentEncoding = "content-encoding"
+const HeaderContentLanguage = "content-language"
+const HeaderContentMD5 = "content-md5"
+const HeaderContentRange = "content-range"
+const HeaderContentType = "content-type"
+const HeaderDate = "date"
+const HeaderETag = "etag"
+const HeaderExpires = "expires"
+const HeaderIfMatch = "if-match"
+const HeaderIfModifiedSince = "if-modified-since"
+const HeaderIfNoneMatch = "if-none-match"
+const HeaderIfUnmodifiedSince = "if-unmodified-since"
+const HeaderLastModified = "last-modified"
+const HeaderLocation = "location"
+const HeaderRange = "range"
+const HeaderXAmzACL = "x-amz-acl"
+const HeaderXAmzAccessPointAlias = "x-amz-access-point-alias"
+const HeaderXAmzBucketObjectLockEnabled = "x-amz-bucket-object-lock-enabled"
+const HeaderXAmzBucketRegion = "x-amz-bucket-region"
+const HeaderXAmzChecksumAlgorithm = "x-amz-checksum-algorithm"
 const HeaderXAmzChecksumCrc32 = "x-amz-checksum-crc32"
 const HeaderXAmzChecksumCrc32c = "x-amz-checksum-crc32c"
 const HeaderXAmzChecksumCrc64nvme = "x-amz-checksum-crc64nvme"
+const HeaderXAmzChecksumMode = "x-amz-checksum-mode"
 const HeaderXAmzChecksumSHA1 = "x-amz-checksum-sha1"
 const HeaderXAmzChecksumSHA256 = "x-amz-checksum-sha256"
+const HeaderXAmzChecksumType = "x-amz-checksum-type"
 const HeaderXAmzContentSHA256 = "x-amz-content-sha256"
+const HeaderXAmzCopySource = "x-amz-copy-source"
+const HeaderXAmzCopySourceIfMatch = "x-amz-copy-source-if-match"
+const HeaderXAmzCopySourceIfModifiedSince = "x-amz-copy-source-if-modified-since"
+const HeaderXAmzCopySourceIfNoneMatch = "x-amz-copy-source-if-none-match"
+const HeaderXAmzCopySourceIfUnmodifiedSince = "x-amz-copy-source-if-unmodified-since"
+const HeaderXAmzCopySourceRange = "x-amz-copy-source-range"
+const HeaderXAmzCopySourceServerSideEncryptionCustomerAlgorithm = "x-amz-copy-source-server-side-encryption-customer-algorithm"
+const HeaderXAmzCopySourceServerSideEncryptionCustomerKey = "x-amz-copy-source-server-side-encryption-customer-key"
+const HeaderXAmzCopySourceServerSideEncryptionCustomerKeyMD5 = "x-amz-copy-source-server-side-encryption-customer-key-md5"
+const HeaderXAmzCopySourceVersionID = "x-amz-copy-source-version-id"
 const HeaderXAmzDate = "x-amz-date"
+const HeaderXAmzCreateSessionMode = "x-amz-create-session-mode"
 const HeaderXAmzDecodedContentLength = "x-amz-decoded-content-length"
+const HeaderXAmzMetaPrefix = "x-amz-meta-"
+const HeaderXAmzMetadataDirective = "x-amz-metadata-directive"
+const HeaderXAmzMpPartsCount = "x-amz-mp-parts-count"
+const HeaderXAmzObjectOwnership = "x-amz-object-ownership"
+const HeaderXAmzS3SessionToken = "x-amz-s3session-token"
+const HeaderXAmzSecurityToken = "x-amz-security-token"
+const HeaderXAmzServerSideEncryption = "x-amz-server-side-encryption"
+const HeaderXAmzServerSideEncryptionCustomerAlgorithm = "x-amz-server-side-encryption-customer-algorithm"
+const HeaderXAmzServerSideEncryptionCustomerKey = "x-amz-server-side-encryption-customer-key"
+const HeaderXAmzServerSideEncryptionCustomerKeyMD5 = "x-amz-server-side-encryption-customer-key-md5"
+const HeaderXAmzStorageClass = "x-amz-storage-class"
+const HeaderXAmzTagging = "x-amz-tagging"
+const HeaderXAmzTaggingDirective = "x-amz-tagging-directive"
 const HeaderXAmzTrailer = "x-amz-trailer"
 const HeaderXAmzTrailerSignature = "x-amz-trailer-signature"
+const HeaderXAmzVersionID = "x-amz-version-id"
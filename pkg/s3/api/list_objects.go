@@ -0,0 +1,52 @@
+package api
+
+import (
+	"time"
+)
+
+type ListObjectsResult struct {
+	Name           string         `xml:"Name"`
+	Prefix         string         `xml:"Prefix"`
+	Delimiter      string         `xml:"Delimiter"`
+	Marker         string         `xml:"Marker"`
+	NextMarker     string         `xml:"NextMarker"`
+	EncodingType   string         `xml:"EncodingType"`
+	MaxKeys        int            `xml:"MaxKeys"`
+	IsTruncated    bool           `xml:"IsTruncated"`
+	Contents       []Object       `xml:"Contents"`
+	CommonPrefixes []CommonPrefix `xml:"CommonPrefixes"`
+}
+
+type ListObjectsV2Result struct {
+	Name                  string         `xml:"Name"`
+	Prefix                string         `xml:"Prefix"`
+	Delimiter             string         `xml:"Delimiter"`
+	StartAfter            string         `xml:"StartAfter"`
+	EncodingType          string         `xml:"EncodingType"`
+	MaxKeys               int            `xml:"MaxKeys"`
+	KeyCount              int            `xml:"KeyCount"`
+	IsTruncated           bool           `xml:"IsTruncated"`
+	ContinuationToken     string         `xml:"ContinuationToken"`
+	NextContinuationToken string         `xml:"NextContinuationToken"`
+	Contents              []Object       `xml:"Contents"`
+	CommonPrefixes        []CommonPrefix `xml:"CommonPrefixes"`
+}
+
+type Object struct {
+	Key               string    `xml:"Key"`
+	LastModified      time.Time `xml:"LastModified"`
+	ETag              string    `xml:"ETag"`
+	Size              int64     `xml:"Size"`
+	StorageClass      string    `xml:"StorageClass"`
+	ChecksumAlgorithm []string  `xml:"ChecksumAlgorithm"`
+	Owner             *Owner    `xml:"Owner"`
+}
+
+type Owner struct {
+	ID          string `xml:"ID"`
+	DisplayName string `xml:"DisplayName"`
+}
+
+type CommonPrefix struct {
+	Prefix string `xml:"Prefix"`
+}
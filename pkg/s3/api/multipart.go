@@ -0,0 +1,89 @@
+package api
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// Checksums are the base64 encoded checksums of a part or an object.
+type Checksums struct {
+	ChecksumCRC32     string `xml:"ChecksumCRC32,omitempty"`
+	ChecksumCRC32C    string `xml:"ChecksumCRC32C,omitempty"`
+	ChecksumCRC64NVME string `xml:"ChecksumCRC64NVME,omitempty"`
+	ChecksumSHA1      string `xml:"ChecksumSHA1,omitempty"`
+	ChecksumSHA256    string `xml:"ChecksumSHA256,omitempty"`
+}
+
+type InitiateMultipartUploadResult struct {
+	Bucket   string `xml:"Bucket"`
+	Key      string `xml:"Key"`
+	UploadID string `xml:"UploadId"`
+}
+
+type CompleteMultipartUpload struct {
+	XMLName xml.Name        `xml:"http://s3.amazonaws.com/doc/2006-03-01/ CompleteMultipartUpload"`
+	Parts   []CompletedPart `xml:"Part"`
+}
+
+type CompletedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+	Checksums
+}
+
+type CompleteMultipartUploadResult struct {
+	Location     string `xml:"Location"`
+	Bucket       string `xml:"Bucket"`
+	Key          string `xml:"Key"`
+	ETag         string `xml:"ETag"`
+	ChecksumType string `xml:"ChecksumType"`
+	Checksums
+}
+
+type ListPartsResult struct {
+	Bucket               string `xml:"Bucket"`
+	Key                  string `xml:"Key"`
+	UploadID             string `xml:"UploadId"`
+	PartNumberMarker     int    `xml:"PartNumberMarker"`
+	NextPartNumberMarker int    `xml:"NextPartNumberMarker"`
+	MaxParts             int    `xml:"MaxParts"`
+	IsTruncated          bool   `xml:"IsTruncated"`
+	StorageClass         string `xml:"StorageClass"`
+	ChecksumAlgorithm    string `xml:"ChecksumAlgorithm"`
+	ChecksumType         string `xml:"ChecksumType"`
+	Parts                []Part `xml:"Part"`
+}
+
+type Part struct {
+	PartNumber   int       `xml:"PartNumber"`
+	LastModified time.Time `xml:"LastModified"`
+	ETag         string    `xml:"ETag"`
+	Size         int64     `xml:"Size"`
+	Checksums
+}
+
+type ListMultipartUploadsResult struct {
+	Bucket             string            `xml:"Bucket"`
+	Prefix             string            `xml:"Prefix"`
+	Delimiter          string            `xml:"Delimiter"`
+	KeyMarker          string            `xml:"KeyMarker"`
+	UploadIDMarker     string            `xml:"UploadIdMarker"`
+	NextKeyMarker      string            `xml:"NextKeyMarker"`
+	NextUploadIDMarker string            `xml:"NextUploadIdMarker"`
+	EncodingType       string            `xml:"EncodingType"`
+	MaxUploads         int               `xml:"MaxUploads"`
+	IsTruncated        bool              `xml:"IsTruncated"`
+	Uploads            []MultipartUpload `xml:"Upload"`
+	CommonPrefixes     []CommonPrefix    `xml:"CommonPrefixes"`
+}
+
+type MultipartUpload struct {
+	Key               string    `xml:"Key"`
+	UploadID          string    `xml:"UploadId"`
+	Initiated         time.Time `xml:"Initiated"`
+	StorageClass      string    `xml:"StorageClass"`
+	ChecksumAlgorithm string    `xml:"ChecksumAlgorithm"`
+	ChecksumType      string    `xml:"ChecksumType"`
+	Owner             *Owner    `xml:"Owner"`
+	Initiator         *Owner    `xml:"Initiator"`
+}
@@ -0,0 +1,16 @@
+package api
+
+import (
+	"time"
+)
+
+type CreateSessionResult struct {
+	Credentials SessionCredentials `xml:"Credentials"`
+}
+
+type SessionCredentials struct {
+	AccessKeyID     string    `xml:"AccessKeyId"`
+	SecretAccessKey string    `xml:"SecretAccessKey"`
+	SessionToken    string    `xml:"SessionToken"`
+	Expiration      time.Time `xml:"Expiration"`
+}
@@ -0,0 +1,19 @@
+package api
+
+import "encoding/xml"
+
+type Tagging struct {
+	XMLName xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ Tagging"`
+	TagSet  []Tag    `xml:"TagSet>Tag"`
+}
+
+// TaggingResult is the Tagging document returned by S3, whatever its
+// namespace.
+type TaggingResult struct {
+	TagSet []Tag `xml:"TagSet>Tag"`
+}
+
+type Tag struct {
+	Key   string `xml:"Key"`
+	Value string `xml:"Value"`
+}
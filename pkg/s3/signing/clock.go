@@ -0,0 +1,57 @@
+package signing
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is the time source used for signing and expiration checks.
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock relies on the local system time.
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// SigningTimeNow returns the current signing time according to the clock.
+func SigningTimeNow(clock Clock) SigningTime {
+	return SigningTimeOf(clock.Now())
+}
+
+// FakeClock is a manually driven clock for deterministic tests.
+// It is safe for concurrent use.
+type FakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *FakeClock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = now
+}
+
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
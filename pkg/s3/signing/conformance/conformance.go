@@ -0,0 +1,130 @@
+// Package conformance exposes the vectors of the aws-sig-v4-test-suite so that
+// any SigV4 signer or verifier can be checked against them.
+//
+// Every vector is a directory holding the files:
+//
+//   - <name>.req: the request to sign
+//   - <name>.creq: the expected canonical request
+//   - <name>.sts: the expected string to sign
+//   - <name>.authz: the expected Authorization header
+//   - <name>.sreq: the signed request
+//
+// The vectors are signed with the Credentials, Region, Service and SigningTime
+// of this package. The vectors of the suite missing from testdata are listed
+// in Excluded.
+package conformance
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/lvjp/s3hobby/pkg/s3/signing"
+)
+
+const (
+	Region  = "us-east-1"
+	Service = "service"
+)
+
+var Credentials = signing.Credentials{
+	AccessKeyID:     "AKIDEXAMPLE",
+	SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+}
+
+var SigningTime = signing.SigningTimeOf(time.Date(2015, time.August, 30, 12, 36, 0, 0, time.UTC))
+
+//go:embed testdata/aws-sig-v4-test-suite
+var suite embed.FS
+
+type Vector struct {
+	// Name is the path of the vector directory, e.g. post-sts-token/post-sts-header-before.
+	Name string
+
+	Request          []byte
+	CanonicalRequest string
+	StringToSign     string
+	Authorization    string
+	SignedRequest    []byte
+}
+
+// Excluded maps the vectors of the aws-sig-v4-test-suite which are not
+// embedded to the reason of their exclusion.
+var Excluded = map[string]string{
+	"get-header-value-multiline": "the header value is folded over several lines, which HTTP/1.1 obsoletes and fasthttp cannot send",
+
+	"normalize-path/get-relative":            pathNormalization,
+	"normalize-path/get-relative-relative":   pathNormalization,
+	"normalize-path/get-slash":               pathNormalization,
+	"normalize-path/get-slash-dot-slash":     pathNormalization,
+	"normalize-path/get-slash-pointless-dot": pathNormalization,
+	"normalize-path/get-slashes":             pathNormalization,
+
+	"post-sts-token/post-sts-header-after": "the session token is added after signing, while S3 requires it to be signed",
+}
+
+const pathNormalization = "the path is normalized before signing, while S3 keys are signed as is"
+
+// Vectors returns the vectors of the embedded test suite.
+func Vectors() ([]Vector, error) {
+	root, err := fs.Sub(suite, "testdata/aws-sig-v4-test-suite")
+	if err != nil {
+		return nil, err
+	}
+
+	return Load(root)
+}
+
+// Load reads every vector found in fsys, sorted by name.
+func Load(fsys fs.FS) ([]Vector, error) {
+	var vectors []Vector
+
+	err := fs.WalkDir(fsys, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() || path.Ext(name) != ".req" {
+			return nil
+		}
+
+		vector, err := loadVector(fsys, strings.TrimSuffix(name, ".req"))
+		if err != nil {
+			return err
+		}
+
+		vectors = append(vectors, vector)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return vectors, nil
+}
+
+func loadVector(fsys fs.FS, base string) (Vector, error) {
+	files := make(map[string][]byte, 5) //nolint:mnd // One file per extension
+
+	for _, ext := range []string{".req", ".creq", ".sts", ".authz", ".sreq"} {
+		content, err := fs.ReadFile(fsys, base+ext)
+		if err != nil {
+			return Vector{}, fmt.Errorf("conformance: cannot load vector %q: %w", path.Dir(base), err)
+		}
+
+		files[ext] = content
+	}
+
+	return Vector{
+		Name:             path.Dir(base),
+		Request:          files[".req"],
+		CanonicalRequest: string(files[".creq"]),
+		StringToSign:     string(files[".sts"]),
+		Authorization:    string(files[".authz"]),
+		SignedRequest:    files[".sreq"],
+	}, nil
+}
@@ -0,0 +1,117 @@
+package conformance
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVectors(t *testing.T) {
+	vectors, err := Vectors()
+	require.NoError(t, err)
+	require.NotEmpty(t, vectors)
+
+	names := make([]string, 0, len(vectors))
+	for _, vector := range vectors {
+		names = append(names, vector.Name)
+
+		assert.NotEmpty(t, vector.Request, vector.Name)
+		assert.NotEmpty(t, vector.CanonicalRequest, vector.Name)
+		assert.NotEmpty(t, vector.StringToSign, vector.Name)
+		assert.NotEmpty(t, vector.Authorization, vector.Name)
+		assert.NotEmpty(t, vector.SignedRequest, vector.Name)
+	}
+
+	assert.Contains(t, names, "get-vanilla")
+	assert.Contains(t, names, "post-sts-token/post-sts-header-before")
+	assert.Contains(t, names, "normalize-path/get-space")
+
+	for name := range Excluded {
+		assert.NotContains(t, names, name, "excluded vectors must not be embedded")
+	}
+}
+
+func TestParseRequest(t *testing.T) {
+	testCases := []struct {
+		name string
+		raw  string
+
+		method   string
+		path     string
+		rawQuery string
+		headers  map[string][]string
+		body     string
+	}{
+		{
+			name:     "unescaped target",
+			raw:      "GET /example space/?ሴ=bar HTTP/1.1\nHost:example.amazonaws.com\nX-Amz-Date:20150830T123600Z",
+			method:   "GET",
+			path:     "/example space/",
+			rawQuery: "ሴ=bar",
+			headers: map[string][]string{
+				"X-Amz-Date": {"20150830T123600Z"},
+			},
+		},
+		{
+			name:   "untrimmed and repeated headers",
+			raw:    "GET / HTTP/1.1\nHost:example.amazonaws.com\nMy-Header1: value2\nMy-Header1:value1 ",
+			method: "GET",
+			path:   "/",
+			headers: map[string][]string{
+				"My-Header1": {" value2", "value1 "},
+			},
+		},
+		{
+			name:   "body with CRLF",
+			raw:    "POST / HTTP/1.1\r\nContent-Type:application/x-www-form-urlencoded\r\nHost:example.amazonaws.com\r\n\r\nParam1=value1",
+			method: "POST",
+			path:   "/",
+			headers: map[string][]string{
+				"Content-Type": {"application/x-www-form-urlencoded"},
+			},
+			body: "Param1=value1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := ParseRequest([]byte(tc.raw))
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.method, req.Method)
+			assert.Equal(t, "example.amazonaws.com", req.Host)
+			assert.Equal(t, tc.path, req.URL.Path)
+			assert.Equal(t, tc.rawQuery, req.URL.RawQuery)
+
+			for key, values := range tc.headers {
+				assert.Equal(t, values, req.Header.Values(key), key)
+			}
+
+			body, err := io.ReadAll(req.Body)
+			require.NoError(t, err)
+			assert.Equal(t, tc.body, string(body))
+		})
+	}
+}
+
+func TestParseRequestErrors(t *testing.T) {
+	testCases := []struct {
+		name string
+		raw  string
+	}{
+		{name: "request line", raw: "GET\nHost:example.amazonaws.com"},
+		{name: "protocol", raw: "GET / FTP\nHost:example.amazonaws.com"},
+		{name: "missing host", raw: "GET / HTTP/1.1\nX-Amz-Date:20150830T123600Z"},
+		{name: "header line", raw: "GET / HTTP/1.1\nHost:example.amazonaws.com\nMy-Header1"},
+		{name: "folded header", raw: "GET / HTTP/1.1\nHost:example.amazonaws.com\nMy-Header1:value1\n  value2"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseRequest([]byte(tc.raw))
+			require.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package conformance
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ParseRequest parses a request in the .req or .sreq format of the test suite.
+//
+// The format looks like HTTP/1.1 but is more lenient: the request target is not
+// escaped and may contain spaces or UTF-8 characters, and header values are kept
+// untrimmed. Both LF and CRLF line endings are accepted.
+func ParseRequest(raw []byte) (*http.Request, error) {
+	head, body := splitHeadBody(raw)
+
+	lines := strings.Split(strings.ReplaceAll(string(head), "\r\n", "\n"), "\n")
+
+	method, target, err := parseRequestLine(lines[0])
+	if err != nil {
+		return nil, err
+	}
+
+	var host string
+
+	header := make(http.Header)
+
+	for _, line := range lines[1:] {
+		if line == "" {
+			continue
+		}
+
+		if line[0] == ' ' || line[0] == '\t' {
+			return nil, fmt.Errorf("conformance: folded header line %q is not supported", line)
+		}
+
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			return nil, fmt.Errorf("conformance: malformed header line %q", line)
+		}
+
+		if strings.EqualFold(key, "host") {
+			host = value
+			continue
+		}
+
+		header.Add(key, value)
+	}
+
+	if host == "" {
+		return nil, errors.New("conformance: missing host header")
+	}
+
+	rawPath, rawQuery, _ := strings.Cut(target, "?")
+
+	path, err := url.PathUnescape(rawPath)
+	if err != nil {
+		return nil, fmt.Errorf("conformance: malformed path %q: %w", rawPath, err)
+	}
+
+	uri := url.URL{
+		Scheme:   "https",
+		Host:     host,
+		Path:     path,
+		RawQuery: rawQuery,
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), method, uri.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("conformance: cannot build request: %w", err)
+	}
+
+	req.Header = header
+	req.Host = host
+
+	return req, nil
+}
+
+func splitHeadBody(raw []byte) (head, body []byte) {
+	for _, separator := range []string{"\r\n\r\n", "\n\n"} {
+		if before, after, found := bytes.Cut(raw, []byte(separator)); found {
+			return before, after
+		}
+	}
+
+	return raw, nil
+}
+
+func parseRequestLine(line string) (method, target string, err error) {
+	method, rest, found := strings.Cut(line, " ")
+	if !found {
+		return "", "", fmt.Errorf("conformance: malformed request line %q", line)
+	}
+
+	// The target may contain spaces, the protocol is always the last field.
+	separator := strings.LastIndexByte(rest, ' ')
+	if separator < 0 || !strings.HasPrefix(rest[separator+1:], "HTTP/") {
+		return "", "", fmt.Errorf("conformance: malformed request line %q", line)
+	}
+
+	return method, rest[:separator], nil
+}
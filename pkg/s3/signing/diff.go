@@ -0,0 +1,98 @@
+package signing
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/lvjp/s3hobby/pkg/s3/api"
+)
+
+// DiffMismatch parses a SignatureDoesNotMatch error document and returns a
+// line-level diff between what the server expected and what was signed.
+func (r SigningResult) DiffMismatch(errorBody []byte) (string, error) {
+	var apiErr api.Error
+	if err := xml.Unmarshal(errorBody, &apiErr); err != nil {
+		return "", fmt.Errorf("DiffMismatch: cannot parse error document: %w", err)
+	}
+
+	if apiErr.Code != api.ErrorCodeSignatureDoesNotMatch {
+		return "", fmt.Errorf("DiffMismatch: expected %q error code, got %q", api.ErrorCodeSignatureDoesNotMatch, apiErr.Code)
+	}
+
+	return r.DiffError(&apiErr), nil
+}
+
+// DiffError returns a line-level diff between the canonical request and the
+// string to sign computed by the server and the signed ones.
+// Lines only expected by the server are prefixed by "-", lines only signed by
+// the client are prefixed by "+".
+func (r SigningResult) DiffError(apiErr *api.Error) string {
+	var ret strings.Builder
+
+	writeDiff(&ret, "canonical request", apiErr.CanonicalRequest, r.CanonicalRequest)
+	writeDiff(&ret, "string to sign", apiErr.StringToSign, r.StringToSign)
+
+	return ret.String()
+}
+
+func writeDiff(w *strings.Builder, title, server, client string) {
+	if server == client {
+		fmt.Fprintf(w, "%s: identical\n", title)
+		return
+	}
+
+	fmt.Fprintf(w, "%s:\n", title)
+
+	for _, line := range diffLines(strings.Split(server, "\n"), strings.Split(client, "\n")) {
+		w.WriteString(line)
+		w.WriteByte('\n')
+	}
+}
+
+// diffLines computes the longest common subsequence of both sides and returns
+// every line prefixed by its diff marker.
+func diffLines(a, b []string) []string {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	ret := make([]string, 0, max(len(a), len(b)))
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ret = append(ret, "  "+a[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ret = append(ret, "- "+a[i])
+			i++
+		default:
+			ret = append(ret, "+ "+b[j])
+			j++
+		}
+	}
+
+	for ; i < len(a); i++ {
+		ret = append(ret, "- "+a[i])
+	}
+
+	for ; j < len(b); j++ {
+		ret = append(ret, "+ "+b[j])
+	}
+
+	return ret
+}
@@ -0,0 +1,95 @@
+package signing
+
+import (
+	"net/url"
+	"strings"
+	"unsafe"
+
+	"github.com/valyala/fasthttp"
+)
+
+// NewFastHTTPRequest adapts a fasthttp request to the Request interface. It
+// disables the path normalization of the request so that the sent path is the
+// signed one, a fasthttp.Client or HostClient sending it must also have
+// DisablePathNormalizing set since they override the setting of each request.
+func NewFastHTTPRequest(req *fasthttp.Request) Request {
+	req.URI().DisablePathNormalizing = true
+
+	return &fasthttpRequest{req: req}
+}
+
+type fasthttpRequest struct {
+	req *fasthttp.Request
+}
+
+func (r *fasthttpRequest) Method() string {
+	return b2s(r.req.Header.Method())
+}
+
+func (r *fasthttpRequest) Scheme() string {
+	return b2s(r.req.URI().Scheme())
+}
+
+func (r *fasthttpRequest) Host() string {
+	return b2s(r.req.Host())
+}
+
+func (r *fasthttpRequest) Path() string {
+	// PathOriginal is the path sent as long as normalization is disabled, Path
+	// would alter object keys like "a//b".
+	path := b2s(r.req.URI().PathOriginal())
+	if strings.IndexByte(path, '%') < 0 {
+		return path
+	}
+
+	if unescaped, err := url.PathUnescape(path); err == nil {
+		return unescaped
+	}
+
+	return path
+}
+
+func (r *fasthttpRequest) AppendQueryArgs(dst []Field) []Field {
+	for key, value := range r.req.URI().QueryArgs().All() {
+		dst = append(dst, Field{Key: b2s(key), Value: b2s(value)})
+	}
+
+	return dst
+}
+
+func (r *fasthttpRequest) Header(key string) string {
+	return b2s(r.req.Header.Peek(key))
+}
+
+func (r *fasthttpRequest) AppendHeaders(dst []Field) []Field {
+	for key, value := range r.req.Header.All() {
+		dst = append(dst, Field{Key: b2s(key), Value: b2s(value)})
+	}
+
+	return dst
+}
+
+func (r *fasthttpRequest) SetHeader(key, value string) {
+	r.req.Header.Set(key, value)
+}
+
+func (r *fasthttpRequest) DelHeader(key string) {
+	r.req.Header.Del(key)
+}
+
+func (r *fasthttpRequest) SetContentLength(contentLength int) {
+	r.req.Header.SetContentLength(contentLength)
+}
+
+func (r *fasthttpRequest) Body() ([]byte, error) {
+	return r.req.Body(), nil
+}
+
+func (r *fasthttpRequest) SetBody(body []byte) {
+	r.req.SetBodyRaw(body)
+}
+
+// b2s converts without copy, see the Request documentation about validity.
+func b2s(b []byte) string {
+	return unsafe.String(unsafe.SliceData(b), len(b)) //nolint:gosec // Zero-copy conversion on purpose
+}
@@ -0,0 +1,20 @@
+package signing_test
+
+import (
+	"testing"
+
+	"github.com/lvjp/s3hobby/pkg/s3/signing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func TestFastHTTPRequestPath(t *testing.T) {
+	var req fasthttp.Request
+	req.SetRequestURI("https://examplebucket.s3.amazonaws.com/a//b/./../c%20d%2Be")
+
+	signingReq := signing.NewFastHTTPRequest(&req)
+
+	assert.Equal(t, "/a//b/./../c d+e", signingReq.Path())
+	assert.Equal(t, "/a//b/./../c%20d%2Be", string(req.URI().RequestURI()), "the sent path must be the signed one")
+}
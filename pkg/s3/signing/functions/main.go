@@ -4,7 +4,6 @@ import (
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
-	"fmt"
 	"strings"
 )
 
@@ -41,6 +40,42 @@ func Trim(v string) string {
 	return strings.TrimSpace(v)
 }
 
+// TrimAll implementation following AWS specification:
+// Remove excess white space before and after values, and convert sequential spaces to a single space.
+func TrimAll(v string) string {
+	return string(AppendTrimAll(make([]byte, 0, len(v)), v))
+}
+
+// AppendTrimAll appends TrimAll(v) to dst without intermediate allocation.
+func AppendTrimAll(dst []byte, v string) []byte {
+	v = Trim(v)
+
+	for i := range len(v) {
+		if v[i] == ' ' && i > 0 && v[i-1] == ' ' {
+			continue
+		}
+
+		dst = append(dst, v[i])
+	}
+
+	return dst
+}
+
+// AppendLowerCase appends v to dst with ASCII letters converted to lowercase.
+// It is meant for header names which are restricted to ASCII.
+func AppendLowerCase(dst []byte, v string) []byte {
+	for i := range len(v) {
+		c := v[i]
+		if c >= 'A' && c <= 'Z' {
+			c += 'a' - 'A'
+		}
+
+		dst = append(dst, c)
+	}
+
+	return dst
+}
+
 // URIEncode URI encode every byte. UriEncode() must enforce the following rules:
 // URI encode every byte except the unreserved characters: 'A'-'Z', 'a'-'z', '0'-'9', '-', '.', '_', and '~'.
 // The space character is a reserved character and must be encoded as "%20" (and not as "+").
@@ -48,19 +83,24 @@ func Trim(v string) string {
 // Letters in the hexadecimal value must be uppercase, for example "%1A".
 // Encode the forward slash character, '/', everywhere except in the object key name. For example, if the object key name is photos/Jan/sample.jpg, the forward slash in the key name is not encoded.
 func URIEncode(v string, isObjectKey bool) string {
-	var ret strings.Builder
+	return string(AppendURIEncode(make([]byte, 0, len(v)), v, isObjectKey))
+}
+
+// AppendURIEncode appends URIEncode(v, isObjectKey) to dst without intermediate allocation.
+func AppendURIEncode(dst []byte, v string, isObjectKey bool) []byte {
+	const upperHex = "0123456789ABCDEF"
 
 	for i := range len(v) {
 		c := v[i]
 		if !shouldEscape(c, isObjectKey) {
-			ret.WriteByte(c)
+			dst = append(dst, c)
 			continue
 		}
 
-		ret.WriteString(fmt.Sprintf("%%%02X", c))
+		dst = append(dst, '%', upperHex[c>>4], upperHex[c&0x0F])
 	}
 
-	return ret.String()
+	return dst
 }
 
 func shouldEscape(v byte, isObjectKey bool) bool {
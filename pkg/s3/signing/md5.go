@@ -0,0 +1,39 @@
+package signing
+
+import (
+	"crypto/md5" //nolint:gosec // Content-MD5 is an integrity check required by S3, not a security feature
+	"encoding/base64"
+	"fmt"
+
+	"github.com/lvjp/s3hobby/pkg/s3/api"
+)
+
+// ContentMD5 returns the base64 encoded MD5 digest of body, as expected by the
+// Content-MD5 header.
+func ContentMD5(body []byte) string {
+	sum := md5.Sum(body) //nolint:gosec // See the import comment
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// SetContentMD5 sets the Content-MD5 header of requests having a body, unless
+// it is already set. It must be called before signing so that the header is
+// signed, the digest is always computed on the original body even when it is
+// later streamed with the aws-chunked encoding.
+func SetContentMD5(req Request) error {
+	if req.Header(api.HeaderContentMD5) != "" {
+		return nil
+	}
+
+	body, err := req.Body()
+	if err != nil {
+		return fmt.Errorf("SetContentMD5: cannot read body: %w", err)
+	}
+
+	if len(body) == 0 {
+		return nil
+	}
+
+	req.SetHeader(api.HeaderContentMD5, ContentMD5(body))
+
+	return nil
+}
@@ -0,0 +1,184 @@
+package signing
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// NewHTTPRequest adapts a net/http request to the Request interface.
+// The body is fully read on the first call to Body.
+func NewHTTPRequest(req *http.Request) Request {
+	return &httpRequest{req: req}
+}
+
+type httpRequest struct {
+	req *http.Request
+
+	body     []byte
+	bodyRead bool
+}
+
+func (r *httpRequest) Method() string {
+	return r.req.Method
+}
+
+func (r *httpRequest) Scheme() string {
+	return r.req.URL.Scheme
+}
+
+func (r *httpRequest) Host() string {
+	if r.req.Host != "" {
+		return r.req.Host
+	}
+
+	return r.req.URL.Host
+}
+
+func (r *httpRequest) Path() string {
+	return r.req.URL.Path
+}
+
+func (r *httpRequest) AppendQueryArgs(dst []Field) []Field {
+	for key, values := range r.req.URL.Query() {
+		for _, value := range values {
+			dst = append(dst, Field{Key: key, Value: value})
+		}
+	}
+
+	return dst
+}
+
+func (r *httpRequest) Header(key string) string {
+	return r.req.Header.Get(key)
+}
+
+func (r *httpRequest) AppendHeaders(dst []Field) []Field {
+	for key, values := range r.req.Header {
+		for _, value := range values {
+			dst = append(dst, Field{Key: key, Value: value})
+		}
+	}
+
+	return dst
+}
+
+func (r *httpRequest) SetHeader(key, value string) {
+	r.req.Header.Set(key, value)
+}
+
+func (r *httpRequest) DelHeader(key string) {
+	r.req.Header.Del(key)
+}
+
+func (r *httpRequest) SetContentLength(contentLength int) {
+	r.req.ContentLength = int64(contentLength)
+
+	// net/http never sends this header from the map, but it makes the
+	// value visible to the canonicalization like with fasthttp.
+	r.req.Header.Set("Content-Length", strconv.Itoa(contentLength))
+}
+
+func (r *httpRequest) Body() ([]byte, error) {
+	if r.bodyRead {
+		return r.body, nil
+	}
+
+	if r.req.Body != nil && r.req.Body != http.NoBody {
+		body, err := io.ReadAll(r.req.Body)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := r.req.Body.Close(); err != nil {
+			return nil, err
+		}
+
+		r.body = body
+	}
+
+	r.bodyRead = true
+	r.SetBody(r.body)
+
+	return r.body, nil
+}
+
+func (r *httpRequest) SetBody(body []byte) {
+	r.body = body
+	r.bodyRead = true
+
+	if len(body) == 0 {
+		r.req.Body = http.NoBody
+		r.req.GetBody = func() (io.ReadCloser, error) { return http.NoBody, nil }
+		r.req.ContentLength = 0
+
+		return
+	}
+
+	r.req.Body = io.NopCloser(bytes.NewReader(body))
+	r.req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	r.req.ContentLength = int64(len(body))
+}
+
+// RoundTripper signs every outgoing request before handing it to the next
+// round tripper. The original request is never modified.
+type RoundTripper struct {
+	Signer      Signer
+	Credentials Credentials
+	Region      string
+
+	// Clock defaults to SystemClock.
+	Clock Clock
+
+	// ContentMD5 adds a signed Content-MD5 header to requests having a body.
+	ContentMD5 bool
+
+	// Next defaults to http.DefaultTransport.
+	Next http.RoundTripper
+}
+
+func (rt *RoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	signed := req.Clone(req.Context())
+
+	if err := rt.sign(signed); err != nil {
+		if req.Body != nil {
+			_ = req.Body.Close()
+		}
+
+		return nil, err
+	}
+
+	next := rt.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return next.RoundTrip(signed)
+}
+
+func (rt *RoundTripper) sign(req *http.Request) error {
+	signingReq := NewHTTPRequest(req)
+
+	clock := rt.Clock
+	if clock == nil {
+		clock = SystemClock
+	}
+
+	if rt.ContentMD5 {
+		if err := SetContentMD5(signingReq); err != nil {
+			return err
+		}
+	}
+
+	_, err := rt.Signer.Sign(SigningArgs{
+		Request:     signingReq,
+		Credentials: rt.Credentials,
+		Region:      rt.Region,
+		SigningTime: SigningTimeNow(clock),
+	})
+
+	return err
+}
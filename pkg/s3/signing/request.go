@@ -0,0 +1,41 @@
+package signing
+
+// Field is a key/value pair of a header or of the query string.
+type Field struct {
+	Key   string
+	Value string
+}
+
+// Request is the view of an HTTP request needed by signers, so they can be
+// shared between HTTP stacks.
+//
+// Header names are case insensitive.
+//
+// To keep signing allocation free, returned strings may share memory with the
+// underlying request: they are only valid until the request is modified.
+type Request interface {
+	Method() string
+	Scheme() string
+	Host() string
+
+	// Path returns the unescaped request path.
+	Path() string
+
+	// AppendQueryArgs appends the unescaped query parameters to dst,
+	// repeated keys included.
+	AppendQueryArgs(dst []Field) []Field
+
+	// Header returns the first value of the header, or an empty string.
+	Header(key string) string
+
+	// AppendHeaders appends every header to dst, repeated headers included.
+	// The host header may be omitted.
+	AppendHeaders(dst []Field) []Field
+
+	SetHeader(key, value string)
+	DelHeader(key string)
+	SetContentLength(contentLength int)
+
+	Body() ([]byte, error)
+	SetBody(body []byte)
+}
@@ -1,22 +1,46 @@
 package signing
 
-import (
-	"github.com/valyala/fasthttp"
-)
+const ServiceS3 = "s3"
+const ServiceS3Express = "s3express"
 
 type Credentials struct {
 	AccessKeyID     string
 	SecretAccessKey string
+
+	// SessionToken is only set for temporary credentials.
+	SessionToken string
 }
 
 type SigningArgs struct {
-	Request *fasthttp.Request
+	Request Request
 
 	Credentials Credentials
 	Region      string
 	SigningTime SigningTime
+
+	// Service defaults to ServiceS3.
+	Service string
+}
+
+// EffectiveService returns the service name used in the credential scope.
+func (args SigningArgs) EffectiveService() string {
+	if args.Service == "" {
+		return ServiceS3
+	}
+
+	return args.Service
+}
+
+// SigningResult holds the intermediate values computed while signing a request.
+// They are mostly useful for debugging signature mismatches.
+type SigningResult struct {
+	CanonicalRequest string
+	StringToSign     string
+	Signature        string
+	SignedHeaders    string
+	Scope            string
 }
 
 type Signer interface {
-	Sign(args SigningArgs) (canonicalRequest, stringToSign, signature string, err error)
+	Sign(args SigningArgs) (SigningResult, error)
 }
@@ -2,6 +2,8 @@ package signing
 
 import "time"
 
+const formatXAmzDate = "20060102T150405Z"
+
 type SigningTime struct {
 	v time.Time
 }
@@ -19,6 +21,19 @@ func (st SigningTime) ShortFormat() string {
 }
 
 func (st SigningTime) LongFormat() string {
-	const formatXAmzDate = "20060102T150405Z"
 	return st.v.Format(formatXAmzDate)
 }
+
+// ParseSigningTime parses the X-Amz-Date format, e.g. 20150830T123600Z.
+func ParseSigningTime(v string) (SigningTime, error) {
+	t, err := time.Parse(formatXAmzDate, v)
+	if err != nil {
+		return SigningTime{}, err
+	}
+
+	return SigningTimeOf(t), nil
+}
+
+func (st SigningTime) Time() time.Time {
+	return st.v
+}
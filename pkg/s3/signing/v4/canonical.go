@@ -0,0 +1,209 @@
+package v4
+
+import (
+	"bytes"
+	"cmp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/lvjp/s3hobby/pkg/s3/signing"
+	"github.com/lvjp/s3hobby/pkg/s3/signing/functions"
+)
+
+// canonicalBuilder computes the canonical request into reusable buffers, so
+// that signing does not allocate once the buffers have grown.
+type canonicalBuilder struct {
+	fields  []signing.Field
+	entries []canonicalEntry
+
+	// arena stores the normalized keys and values referenced by entries.
+	arena []byte
+
+	canonicalRequest []byte
+	signedHeaders    []byte
+}
+
+// canonicalEntry references a normalized key/value pair inside the arena.
+type canonicalEntry struct {
+	keyStart, keyEnd     int
+	valueStart, valueEnd int
+}
+
+var canonicalBuilderPool = sync.Pool{
+	New: func() any {
+		return &canonicalBuilder{}
+	},
+}
+
+func acquireCanonicalBuilder() *canonicalBuilder {
+	return canonicalBuilderPool.Get().(*canonicalBuilder) //nolint:errcheck // The pool only holds *canonicalBuilder
+}
+
+func releaseCanonicalBuilder(builder *canonicalBuilder) {
+	canonicalBuilderPool.Put(builder)
+}
+
+func (b *canonicalBuilder) key(entry canonicalEntry) []byte {
+	return b.arena[entry.keyStart:entry.keyEnd]
+}
+
+func (b *canonicalBuilder) value(entry canonicalEntry) []byte {
+	return b.arena[entry.valueStart:entry.valueEnd]
+}
+
+// build computes the canonical request. When signedHeaders is not empty, only
+// the headers of this semicolon separated list are canonicalized, otherwise
+// every header is.
+func (b *canonicalBuilder) build(req signing.Request, payloadHash, signedHeaders string) {
+	path := req.Path()
+	if path == "" {
+		path = "/"
+	}
+
+	dst := b.canonicalRequest[:0]
+	dst = append(dst, req.Method()...)
+	dst = append(dst, '\n')
+	dst = functions.AppendURIEncode(dst, path, true)
+	dst = append(dst, '\n')
+	dst = b.appendCanonicalQueryString(dst, req)
+	dst = append(dst, '\n')
+	dst = b.appendCanonicalHeaders(dst, req, signedHeaders)
+	dst = append(dst, '\n')
+	dst = append(dst, b.signedHeaders...)
+	dst = append(dst, '\n')
+	dst = append(dst, payloadHash...)
+
+	b.canonicalRequest = dst
+}
+
+func (b *canonicalBuilder) appendCanonicalQueryString(dst []byte, req signing.Request) []byte {
+	b.fields = req.AppendQueryArgs(b.fields[:0])
+	b.entries = b.entries[:0]
+	b.arena = b.arena[:0]
+
+	// Repeated keys are legitimate (e.g. ?a=1&a=2), every pair must be kept.
+	for _, field := range b.fields {
+		var entry canonicalEntry
+
+		entry.keyStart = len(b.arena)
+		b.arena = functions.AppendURIEncode(b.arena, field.Key, false)
+		entry.keyEnd = len(b.arena)
+
+		entry.valueStart = len(b.arena)
+		b.arena = functions.AppendURIEncode(b.arena, field.Value, false)
+		entry.valueEnd = len(b.arena)
+
+		b.entries = append(b.entries, entry)
+	}
+
+	// Sort by encoded key, then by encoded value for duplicated keys.
+	slices.SortFunc(b.entries, func(x, y canonicalEntry) int {
+		return cmp.Or(
+			bytes.Compare(b.key(x), b.key(y)),
+			bytes.Compare(b.value(x), b.value(y)),
+		)
+	})
+
+	for i, entry := range b.entries {
+		if i > 0 {
+			dst = append(dst, '&')
+		}
+
+		dst = append(dst, b.key(entry)...)
+		dst = append(dst, '=')
+		dst = append(dst, b.value(entry)...)
+	}
+
+	return dst
+}
+
+// appendCanonicalHeaders appends the canonical headers and computes the signed
+// headers list at the same time.
+func (b *canonicalBuilder) appendCanonicalHeaders(dst []byte, req signing.Request, include string) []byte {
+	b.fields = req.AppendHeaders(b.fields[:0])
+	b.entries = b.entries[:0]
+	b.arena = b.arena[:0]
+
+	hasHost := false
+
+	for _, field := range b.fields {
+		var entry canonicalEntry
+
+		entry.keyStart = len(b.arena)
+		b.arena = functions.AppendLowerCase(b.arena, field.Key)
+		entry.keyEnd = len(b.arena)
+
+		if !isIncludedHeader(include, b.key(entry)) {
+			b.arena = b.arena[:entry.keyStart]
+			continue
+		}
+
+		entry.valueStart = len(b.arena)
+		b.arena = functions.AppendTrimAll(b.arena, field.Value)
+		entry.valueEnd = len(b.arena)
+
+		b.entries = append(b.entries, entry)
+		hasHost = hasHost || string(b.key(entry)) == "host"
+	}
+
+	// Ensure host header presence
+	if !hasHost && isIncludedHeader(include, []byte("host")) {
+		var entry canonicalEntry
+
+		entry.keyStart = len(b.arena)
+		b.arena = append(b.arena, "host"...)
+		entry.keyEnd = len(b.arena)
+
+		entry.valueStart = len(b.arena)
+		b.arena = functions.AppendTrimAll(b.arena, req.Host())
+		entry.valueEnd = len(b.arena)
+
+		b.entries = append(b.entries, entry)
+	}
+
+	// Stable sort keeps the values of a repeated header in their order of appearance.
+	slices.SortStableFunc(b.entries, func(x, y canonicalEntry) int {
+		return bytes.Compare(b.key(x), b.key(y))
+	})
+
+	b.signedHeaders = b.signedHeaders[:0]
+
+	for i, entry := range b.entries {
+		if i > 0 && bytes.Equal(b.key(b.entries[i-1]), b.key(entry)) {
+			dst = append(dst, ',')
+			dst = append(dst, b.value(entry)...)
+
+			continue
+		}
+
+		if i > 0 {
+			dst = append(dst, '\n')
+			b.signedHeaders = append(b.signedHeaders, ';')
+		}
+
+		dst = append(dst, b.key(entry)...)
+		dst = append(dst, ':')
+		dst = append(dst, b.value(entry)...)
+
+		b.signedHeaders = append(b.signedHeaders, b.key(entry)...)
+	}
+
+	return append(dst, '\n')
+}
+
+// isIncludedHeader reports whether the lowercase key is part of the semicolon
+// separated include list, an empty list includes every header.
+func isIncludedHeader(include string, key []byte) bool {
+	if include == "" {
+		return true
+	}
+
+	for name := range strings.SplitSeq(include, ";") {
+		if name == string(key) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,311 @@
+package v4
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lvjp/s3hobby/pkg/s3/signing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+// TestCanonicalRequest check the canonicalization against cases from the
+// official aws-sig-v4-test-suite. Only the canonical request is compared since
+// the suite sign with the "service" service instead of "s3".
+func TestCanonicalRequest(t *testing.T) {
+	const host = "https://example.amazonaws.com"
+	const amzDate = "20150830T123600Z"
+
+	testCases := []struct {
+		name string
+
+		builder  func(req *fasthttp.Request)
+		expected []string
+	}{
+		{
+			name: "get-header-key-duplicate",
+			builder: func(req *fasthttp.Request) {
+				req.Header.SetMethod("GET")
+				req.SetRequestURI(host + "/")
+				req.Header.Add("My-Header1", "value2")
+				req.Header.Add("My-Header1", "value2")
+				req.Header.Add("My-Header1", "value1")
+				req.Header.Set("X-Amz-Date", amzDate)
+			},
+			expected: []string{
+				"GET",
+				"/",
+				"",
+				"host:example.amazonaws.com",
+				"my-header1:value2,value2,value1",
+				"x-amz-date:20150830T123600Z",
+				"",
+				"host;my-header1;x-amz-date",
+				emptyHash,
+			},
+		},
+		{
+			name: "get-header-value-order",
+			builder: func(req *fasthttp.Request) {
+				req.Header.SetMethod("GET")
+				req.SetRequestURI(host + "/")
+				req.Header.Add("My-Header1", "value4")
+				req.Header.Add("My-Header1", "value1")
+				req.Header.Add("My-Header1", "value3")
+				req.Header.Add("My-Header1", "value2")
+				req.Header.Set("X-Amz-Date", amzDate)
+			},
+			expected: []string{
+				"GET",
+				"/",
+				"",
+				"host:example.amazonaws.com",
+				"my-header1:value4,value1,value3,value2",
+				"x-amz-date:20150830T123600Z",
+				"",
+				"host;my-header1;x-amz-date",
+				emptyHash,
+			},
+		},
+		{
+			name: "get-header-value-trim",
+			builder: func(req *fasthttp.Request) {
+				req.Header.SetMethod("GET")
+				req.SetRequestURI(host + "/")
+				req.Header.Set("My-Header1", " value1")
+				req.Header.Set("My-Header2", ` "a   b   c"`)
+				req.Header.Set("X-Amz-Date", amzDate)
+			},
+			expected: []string{
+				"GET",
+				"/",
+				"",
+				"host:example.amazonaws.com",
+				"my-header1:value1",
+				`my-header2:"a b c"`,
+				"x-amz-date:20150830T123600Z",
+				"",
+				"host;my-header1;my-header2;x-amz-date",
+				emptyHash,
+			},
+		},
+		{
+			name: "repeated header with surrounding spaces",
+			builder: func(req *fasthttp.Request) {
+				req.Header.SetMethod("GET")
+				req.SetRequestURI(host + "/")
+				req.Header.Add("My-Header1", "  b  ")
+				req.Header.Add("my-header1", "a   a")
+				req.Header.Set("X-Amz-Date", amzDate)
+			},
+			expected: []string{
+				"GET",
+				"/",
+				"",
+				"host:example.amazonaws.com",
+				"my-header1:b,a a",
+				"x-amz-date:20150830T123600Z",
+				"",
+				"host;my-header1;x-amz-date",
+				emptyHash,
+			},
+		},
+		{
+			name: "get-vanilla-query",
+			builder: func(req *fasthttp.Request) {
+				req.Header.SetMethod("GET")
+				req.SetRequestURI(host + "/?")
+				req.Header.Set("X-Amz-Date", amzDate)
+			},
+			expected: []string{
+				"GET",
+				"/",
+				"",
+				"host:example.amazonaws.com",
+				"x-amz-date:20150830T123600Z",
+				"",
+				"host;x-amz-date",
+				emptyHash,
+			},
+		},
+		{
+			name: "get-vanilla-empty-query-key",
+			builder: func(req *fasthttp.Request) {
+				req.Header.SetMethod("GET")
+				req.SetRequestURI(host + "/?Param1=value1")
+				req.Header.Set("X-Amz-Date", amzDate)
+			},
+			expected: []string{
+				"GET",
+				"/",
+				"Param1=value1",
+				"host:example.amazonaws.com",
+				"x-amz-date:20150830T123600Z",
+				"",
+				"host;x-amz-date",
+				emptyHash,
+			},
+		},
+		{
+			name: "get-vanilla-query-order-key-case",
+			builder: func(req *fasthttp.Request) {
+				req.Header.SetMethod("GET")
+				req.SetRequestURI(host + "/?Param2=value2&Param1=value1")
+				req.Header.Set("X-Amz-Date", amzDate)
+			},
+			expected: []string{
+				"GET",
+				"/",
+				"Param1=value1&Param2=value2",
+				"host:example.amazonaws.com",
+				"x-amz-date:20150830T123600Z",
+				"",
+				"host;x-amz-date",
+				emptyHash,
+			},
+		},
+		{
+			name: "get-vanilla-query-order-value",
+			builder: func(req *fasthttp.Request) {
+				req.Header.SetMethod("GET")
+				req.SetRequestURI(host + "/?Param1=value2&Param1=Value1")
+				req.Header.Set("X-Amz-Date", amzDate)
+			},
+			expected: []string{
+				"GET",
+				"/",
+				"Param1=Value1&Param1=value2",
+				"host:example.amazonaws.com",
+				"x-amz-date:20150830T123600Z",
+				"",
+				"host;x-amz-date",
+				emptyHash,
+			},
+		},
+		{
+			name: "get-vanilla-query-unreserved",
+			builder: func(req *fasthttp.Request) {
+				const unreserved = "-._~0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+				req.Header.SetMethod("GET")
+				req.SetRequestURI(host + "/?" + unreserved + "=" + unreserved)
+				req.Header.Set("X-Amz-Date", amzDate)
+			},
+			expected: []string{
+				"GET",
+				"/",
+				"-._~0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz=-._~0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz",
+				"host:example.amazonaws.com",
+				"x-amz-date:20150830T123600Z",
+				"",
+				"host;x-amz-date",
+				emptyHash,
+			},
+		},
+		{
+			name: "get-vanilla-utf8-query",
+			builder: func(req *fasthttp.Request) {
+				req.Header.SetMethod("GET")
+				req.SetRequestURI(host + "/?ሴ=bar")
+				req.Header.Set("X-Amz-Date", amzDate)
+			},
+			expected: []string{
+				"GET",
+				"/",
+				"%E1%88%B4=bar",
+				"host:example.amazonaws.com",
+				"x-amz-date:20150830T123600Z",
+				"",
+				"host;x-amz-date",
+				emptyHash,
+			},
+		},
+		{
+			name: "repeated query keys without value",
+			builder: func(req *fasthttp.Request) {
+				req.Header.SetMethod("GET")
+				req.SetRequestURI(host + "/?b=2&a=2&acl&a=1&b=1")
+				req.Header.Set("X-Amz-Date", amzDate)
+			},
+			expected: []string{
+				"GET",
+				"/",
+				"a=1&a=2&acl=&b=1&b=2",
+				"host:example.amazonaws.com",
+				"x-amz-date:20150830T123600Z",
+				"",
+				"host;x-amz-date",
+				emptyHash,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var req fasthttp.Request
+			req.Header.SetNoDefaultContentType(true)
+			tc.builder(&req)
+
+			var builder canonicalBuilder
+			builder.build(signing.NewFastHTTPRequest(&req), emptyHash, "")
+
+			assert.Equal(t, strings.Join(tc.expected, "\n"), string(builder.canonicalRequest))
+			assert.Equal(t, tc.expected[len(tc.expected)-2], string(builder.signedHeaders))
+		})
+	}
+}
+
+func BenchmarkCanonicalRequest(b *testing.B) {
+	benchmarks := []struct {
+		name    string
+		builder func(req *fasthttp.Request)
+	}{
+		{
+			name: "small",
+			builder: func(req *fasthttp.Request) {
+				req.Header.SetMethod("GET")
+				req.SetRequestURI("https://examplebucket.s3.amazonaws.com/photos/photo1.jpg")
+				req.Header.Set("X-Amz-Content-Sha256", "UNSIGNED-PAYLOAD")
+				req.Header.Set("X-Amz-Date", "19840805T135000Z")
+			},
+		},
+		{
+			name: "header-heavy",
+			builder: func(req *fasthttp.Request) {
+				req.Header.SetMethod("PUT")
+				req.SetRequestURI("https://examplebucket.s3.amazonaws.com/photos/2024/Jan/photo 1.jpg?partNumber=2&uploadId=VXBsb2FkIElEIGZvciA2aWWpbmcncyBteS1tb3ZpZS5tMnRzIHVwbG9hZA")
+				req.Header.Set("Content-Type", "image/jpeg")
+				req.Header.Set("Cache-Control", "max-age=3600,   must-revalidate")
+				req.Header.Set("Content-Disposition", `attachment; filename="photo 1.jpg"`)
+				req.Header.Set("X-Amz-Content-Sha256", "UNSIGNED-PAYLOAD")
+				req.Header.Set("X-Amz-Date", "19840805T135000Z")
+				req.Header.Set("X-Amz-Storage-Class", "STANDARD_IA")
+				req.Header.Set("X-Amz-Server-Side-Encryption", "aws:kms")
+				req.Header.Set("X-Amz-Checksum-Crc32c", "sOO8/Q==")
+				req.Header.Set("X-Amz-Tagging", "project=hobby&team=s3")
+				for i := range 10 {
+					req.Header.Add("X-Amz-Meta-Key", strings.Repeat("v", i+1))
+				}
+			},
+		},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			var req fasthttp.Request
+			req.Header.SetNoDefaultContentType(true)
+			bm.builder(&req)
+
+			signingReq := signing.NewFastHTTPRequest(&req)
+
+			b.ReportAllocs()
+
+			for b.Loop() {
+				builder := acquireCanonicalBuilder()
+				builder.build(signingReq, "UNSIGNED-PAYLOAD", "")
+				releaseCanonicalBuilder(builder)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package v4
+
+import (
+	"testing"
+
+	"github.com/lvjp/s3hobby/pkg/s3/api"
+	"github.com/lvjp/s3hobby/pkg/s3/signing"
+	"github.com/lvjp/s3hobby/pkg/s3/signing/conformance"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestConformance runs the signer and the verifier against every embedded
+// vector of the aws-sig-v4-test-suite, see conformance.Excluded for the others.
+func TestConformance(t *testing.T) {
+	vectors, err := conformance.Vectors()
+	require.NoError(t, err)
+
+	verifier := &Verifier{
+		Credentials: func(accessKeyID string) (signing.Credentials, error) {
+			assert.Equal(t, conformance.Credentials.AccessKeyID, accessKeyID)
+			return conformance.Credentials, nil
+		},
+		Region:  conformance.Region,
+		Service: conformance.Service,
+		Clock:   signing.NewFakeClock(conformance.SigningTime.Time()),
+	}
+
+	for _, vector := range vectors {
+		t.Run(vector.Name, func(t *testing.T) {
+			t.Run("signer", func(t *testing.T) {
+				req, err := conformance.ParseRequest(vector.Request)
+				require.NoError(t, err)
+
+				result, err := (&GenericSigner{}).Sign(signing.SigningArgs{
+					Request:     signing.NewHTTPRequest(req),
+					Credentials: conformance.Credentials,
+					Region:      conformance.Region,
+					SigningTime: conformance.SigningTime,
+					Service:     conformance.Service,
+				})
+				require.NoError(t, err)
+
+				assert.Equal(t, vector.CanonicalRequest, result.CanonicalRequest)
+				assert.Equal(t, vector.StringToSign, result.StringToSign)
+				assert.Equal(t, vector.Authorization, req.Header.Get(api.HeaderAuthorization))
+			})
+
+			t.Run("verifier", func(t *testing.T) {
+				req, err := conformance.ParseRequest(vector.SignedRequest)
+				require.NoError(t, err)
+
+				result, err := verifier.Verify(signing.NewHTTPRequest(req))
+				require.NoError(t, err)
+
+				assert.Equal(t, vector.CanonicalRequest, result.CanonicalRequest)
+				assert.Equal(t, vector.StringToSign, result.StringToSign)
+			})
+		})
+	}
+}
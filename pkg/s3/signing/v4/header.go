@@ -2,9 +2,6 @@ package v4
 
 import (
 	"fmt"
-	"maps"
-	"slices"
-	"strings"
 
 	"github.com/lvjp/s3hobby/pkg/s3/api"
 	"github.com/lvjp/s3hobby/pkg/s3/signing"
@@ -15,55 +12,103 @@ type PlainPayloadSigner struct {
 	SignPayload bool
 }
 
-func (signer *PlainPayloadSigner) Sign(args signing.SigningArgs) (canonicalRequest, stringToSign, signature string, err error) {
+func (signer *PlainPayloadSigner) Sign(args signing.SigningArgs) (signing.SigningResult, error) {
 	hashPayload := "UNSIGNED-PAYLOAD"
 	if signer.SignPayload {
-		hashPayload = functions.Hex(functions.SHA256Hash(args.Request.Body()))
+		body, err := args.Request.Body()
+		if err != nil {
+			return signing.SigningResult{}, fmt.Errorf("PlainPayloadSigner: cannot read body: %w", err)
+		}
+
+		hashPayload = functions.Hex(functions.SHA256Hash(body))
 	}
-	args.Request.Header.Set(api.HeaderXAmzContentSHA256, hashPayload)
-	args.Request.Header.Set(api.HeaderXAmzDate, args.SigningTime.LongFormat())
+	args.Request.SetHeader(api.HeaderXAmzContentSHA256, hashPayload)
+	args.Request.SetHeader(api.HeaderXAmzDate, args.SigningTime.LongFormat())
+	setSessionToken(args)
 
-	var authorizationHeader string
-	canonicalRequest, stringToSign, signature, authorizationHeader, err = getHeaderSignature(args)
+	result, authorizationHeader, err := getHeaderSignature(args, hashPayload)
 	if err != nil {
-		return "", "", "", err
+		return signing.SigningResult{}, err
 	}
 
-	args.Request.Header.Set(api.HeaderAuthorization, authorizationHeader)
+	args.Request.SetHeader(api.HeaderAuthorization, authorizationHeader)
 
-	return
+	return result, nil
 }
 
-func getHeaderSignature(args signing.SigningArgs) (canonicalRequest, stringToSign, signature, authorizationHeader string, err error) {
-	ctx := newHeaderSigningCtx(args)
+// GenericSigner implements the SigV4 header signing shared by every AWS
+// service. Unlike the S3 signers, the payload hash only appears in the
+// canonical request and no x-amz-content-sha256 header is added.
+type GenericSigner struct{}
+
+func (*GenericSigner) Sign(args signing.SigningArgs) (signing.SigningResult, error) {
+	body, err := args.Request.Body()
+	if err != nil {
+		return signing.SigningResult{}, fmt.Errorf("GenericSigner: cannot read body: %w", err)
+	}
 
-	if actual, expected := ctx.Request.Header.Peek(api.HeaderXAmzDate), ctx.SigningTime.LongFormat(); string(actual) != expected {
-		return "", "", "", "", fmt.Errorf("HeaderSigner: %q header mismatch: expected %q, got %q", ctx.SigningTime.LongFormat(), expected, actual)
+	args.Request.SetHeader(api.HeaderXAmzDate, args.SigningTime.LongFormat())
+	setSessionToken(args)
+
+	result, authorizationHeader, err := getHeaderSignature(args, functions.Hex(functions.SHA256Hash(body)))
+	if err != nil {
+		return signing.SigningResult{}, err
 	}
 
-	payloadHash := string(ctx.Request.Header.Peek(api.HeaderXAmzContentSHA256))
-	if len(payloadHash) == 0 {
-		return "", "", "", "", fmt.Errorf("HeaderSigner: %q header not found", api.HeaderXAmzContentSHA256)
+	args.Request.SetHeader(api.HeaderAuthorization, authorizationHeader)
+
+	return result, nil
+}
+
+func getHeaderSignature(args signing.SigningArgs, payloadHash string) (result signing.SigningResult, authorizationHeader string, err error) {
+	ctx := newHeaderSigningCtx(args)
+
+	if actual, expected := ctx.Request.Header(api.HeaderXAmzDate), ctx.SigningTime.LongFormat(); actual != expected {
+		return signing.SigningResult{}, "", fmt.Errorf("HeaderSigner: %q header mismatch: expected %q, got %q", ctx.SigningTime.LongFormat(), expected, actual)
 	}
 
-	canonicalHeaders, signedHeaders := ctx.computeHeaders()
-	canonicalRequest = ctx.computeCanonicalRequest(canonicalHeaders, signedHeaders, payloadHash)
+	builder := acquireCanonicalBuilder()
+	defer releaseCanonicalBuilder(builder)
+
+	builder.build(ctx.Request, payloadHash, "")
 
 	method := "AWS4-HMAC-SHA256"
-	stringToSignBuilder := NewStringToSignBuilder(ctx.SigningTime, ctx.Region)
-	stringToSign = stringToSignBuilder.BuildWith(method, functions.Hex(functions.SHA256Hash([]byte(canonicalRequest))))
-	signature = ctx.signingKey.Sign([]byte(stringToSign))
+	stringToSignBuilder := NewStringToSignBuilder(ctx.SigningTime, ctx.Region, ctx.EffectiveService())
+	stringToSign := stringToSignBuilder.BuildWith(method, functions.Hex(functions.SHA256Hash(builder.canonicalRequest)))
+
+	result = signing.SigningResult{
+		CanonicalRequest: string(builder.canonicalRequest),
+		StringToSign:     stringToSign,
+		Signature:        ctx.signingKey.Sign([]byte(stringToSign)),
+		SignedHeaders:    string(builder.signedHeaders),
+		Scope:            stringToSignBuilder.Scope(),
+	}
 
 	authorizationHeader = fmt.Sprintf(
 		"%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
 		method,
 		ctx.Credentials.AccessKeyID,
-		stringToSignBuilder.Scope(),
-		signedHeaders,
-		signature,
+		result.Scope,
+		result.SignedHeaders,
+		result.Signature,
 	)
 
-	return canonicalRequest, stringToSign, signature, authorizationHeader, nil
+	return result, authorizationHeader, nil
+}
+
+// setSessionToken adds the session token of temporary credentials, S3 Express
+// One Zone uses a dedicated header for its session credentials.
+func setSessionToken(args signing.SigningArgs) {
+	if args.Credentials.SessionToken == "" {
+		return
+	}
+
+	header := api.HeaderXAmzSecurityToken
+	if args.EffectiveService() == signing.ServiceS3Express {
+		header = api.HeaderXAmzS3SessionToken
+	}
+
+	args.Request.SetHeader(header, args.Credentials.SessionToken)
 }
 
 type headerSigningCtx struct {
@@ -78,74 +123,8 @@ func newHeaderSigningCtx(args signing.SigningArgs) *headerSigningCtx {
 		signingKey: NewSigningKey(
 			args.Credentials,
 			args.Region,
+			args.EffectiveService(),
 			args.SigningTime,
 		),
 	}
 }
-
-func (ctx *headerSigningCtx) computeHeaders() (canonicalHeaders, signedHeaders string) {
-	normalized := make(map[string]string, ctx.Request.Header.Len())
-
-	for key, value := range ctx.Request.Header.All() {
-		normalizedKey := functions.LowerCase(string(key))
-		normalizedValue := functions.Trim(string(value))
-
-		normalized[normalizedKey] = normalizedValue
-	}
-
-	// Ensure host header presence
-	if _, exists := normalized["host"]; !exists {
-		normalized["host"] = string(ctx.Request.Host())
-	}
-
-	sortedHeaders := slices.Sorted(maps.Keys(normalized))
-	for _, key := range sortedHeaders {
-		canonicalHeaders += key + ":" + normalized[key] + "\n"
-	}
-
-	signedHeaders = strings.Join(sortedHeaders, ";")
-
-	return
-}
-
-func (ctx *headerSigningCtx) computeCanonicalRequest(canonicalHeaders, signedHeaders, payloadHash string) string {
-	path := string(ctx.Request.URI().PathOriginal())
-	if path == "" {
-		path = "/"
-	}
-
-	return strings.Join(
-		[]string{
-			string(ctx.Request.Header.Method()),
-			functions.URIEncode(path, true),
-			ctx.getCanonicalQueryString(),
-			canonicalHeaders,
-			signedHeaders,
-			payloadHash,
-		},
-		"\n",
-	)
-}
-
-func (ctx *headerSigningCtx) getCanonicalQueryString() string {
-	args := ctx.Request.URI().QueryArgs()
-	encoded := make(map[string]string, args.Len())
-
-	for key, value := range args.All() {
-		encoded[functions.URIEncode(string(key), false)] = functions.URIEncode(string(value), false)
-	}
-
-	var ret strings.Builder
-
-	for i, key := range slices.Sorted(maps.Keys(encoded)) {
-		if i > 0 {
-			ret.WriteRune('&')
-		}
-
-		ret.WriteString(key)
-		ret.WriteRune('=')
-		ret.WriteString(encoded[key])
-	}
-
-	return ret.String()
-}
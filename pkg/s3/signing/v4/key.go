@@ -12,10 +12,10 @@ type SigningKey struct {
 	v []byte
 }
 
-func NewSigningKey(credentials signing.Credentials, region string, signingTime signing.SigningTime) *SigningKey {
+func NewSigningKey(credentials signing.Credentials, region, service string, signingTime signing.SigningTime) *SigningKey {
 	dateKey := functions.HMAC_SHA256([]byte("AWS4"+credentials.SecretAccessKey), []byte(signingTime.ShortFormat()))
 	dateRegionKey := functions.HMAC_SHA256(dateKey, []byte(region))
-	dateRegionServiceKey := functions.HMAC_SHA256(dateRegionKey, []byte("s3"))
+	dateRegionServiceKey := functions.HMAC_SHA256(dateRegionKey, []byte(service))
 
 	return &SigningKey{
 		v: functions.HMAC_SHA256(dateRegionServiceKey, []byte("aws4_request")),
@@ -31,10 +31,10 @@ type StringToSignBuilder struct {
 	scope       string
 }
 
-func NewStringToSignBuilder(signingTime signing.SigningTime, region string) *StringToSignBuilder {
+func NewStringToSignBuilder(signingTime signing.SigningTime, region, service string) *StringToSignBuilder {
 	return &StringToSignBuilder{
 		signingTime: signingTime.LongFormat(),
-		scope:       signingTime.ShortFormat() + "/" + region + "/s3/aws4_request",
+		scope:       signingTime.ShortFormat() + "/" + region + "/" + service + "/aws4_request",
 	}
 }
 
@@ -0,0 +1,189 @@
+package v4
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+
+	"github.com/lvjp/s3hobby/pkg/s3/signing"
+)
+
+// PostPolicyBuilder builds and signs the policy document of a browser based
+// upload using HTTP POST.
+//
+// Exact match conditions are also returned as form fields, starts-with
+// conditions must be completed by the form itself.
+type PostPolicyBuilder struct {
+	expiration time.Time
+	endpoint   string
+	bucket     string
+
+	conditions []any
+	fields     map[string]string
+
+	// err is the first invalid condition, returned by Sign.
+	err error
+}
+
+func NewPostPolicyBuilder(bucket string, expiration time.Time) *PostPolicyBuilder {
+	return &PostPolicyBuilder{
+		expiration: expiration,
+		bucket:     bucket,
+		conditions: []any{
+			map[string]string{"bucket": bucket},
+		},
+		fields: make(map[string]string),
+	}
+}
+
+// Endpoint sets the base URL of the S3 service, the action URL then uses the
+// path style. Defaults to the virtual hosted style AWS endpoint of the region.
+func (b *PostPolicyBuilder) Endpoint(endpoint string) *PostPolicyBuilder {
+	b.endpoint = endpoint
+	return b
+}
+
+// Key restricts the upload to the exact given key.
+func (b *PostPolicyBuilder) Key(key string) *PostPolicyBuilder {
+	return b.Condition("key", key)
+}
+
+// KeyStartsWith restricts the upload to keys starting with prefix.
+// The key form field defaults to the prefix followed by the uploaded file name.
+func (b *PostPolicyBuilder) KeyStartsWith(prefix string) *PostPolicyBuilder {
+	b.fields["key"] = prefix + "${filename}"
+	return b.StartsWith("key", prefix)
+}
+
+// ContentLengthRange restricts the size in bytes of the uploaded content.
+// Negative lengths and a minimum greater than the maximum make Sign fail.
+func (b *PostPolicyBuilder) ContentLengthRange(minLength, maxLength int64) *PostPolicyBuilder {
+	if (minLength < 0 || maxLength < 0 || minLength > maxLength) && b.err == nil {
+		b.err = fmt.Errorf("PostPolicyBuilder: invalid content length range [%d, %d]", minLength, maxLength)
+	}
+
+	b.conditions = append(b.conditions, []any{"content-length-range", minLength, maxLength})
+	return b
+}
+
+func (b *PostPolicyBuilder) ContentType(contentType string) *PostPolicyBuilder {
+	return b.Condition("Content-Type", contentType)
+}
+
+func (b *PostPolicyBuilder) ContentTypeStartsWith(prefix string) *PostPolicyBuilder {
+	return b.StartsWith("Content-Type", prefix)
+}
+
+// Metadata requires the x-amz-meta-<name> user metadata to be set to value.
+func (b *PostPolicyBuilder) Metadata(name, value string) *PostPolicyBuilder {
+	return b.Condition("x-amz-meta-"+name, value)
+}
+
+// MetadataStartsWith requires the x-amz-meta-<name> user metadata to start with prefix.
+func (b *PostPolicyBuilder) MetadataStartsWith(name, prefix string) *PostPolicyBuilder {
+	return b.StartsWith("x-amz-meta-"+name, prefix)
+}
+
+// Condition adds an exact match condition on a form field.
+func (b *PostPolicyBuilder) Condition(field, value string) *PostPolicyBuilder {
+	b.conditions = append(b.conditions, map[string]string{field: value})
+	b.fields[field] = value
+
+	return b
+}
+
+// StartsWith adds a starts-with condition on a form field.
+func (b *PostPolicyBuilder) StartsWith(field, prefix string) *PostPolicyBuilder {
+	b.conditions = append(b.conditions, []string{"starts-with", "$" + field, prefix})
+	return b
+}
+
+// PostForm holds everything needed to render an HTML upload form. The file
+// field must be added last by the form.
+type PostForm struct {
+	Action string
+	Fields map[string]string
+
+	// Policy is the JSON policy document before its base64 encoding.
+	Policy string
+}
+
+func (b *PostPolicyBuilder) Sign(credentials signing.Credentials, region string, signingTime signing.SigningTime) (*PostForm, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	if b.bucket == "" {
+		return nil, errors.New("PostPolicyBuilder: bucket must be set")
+	}
+
+	if b.expiration.IsZero() {
+		return nil, errors.New("PostPolicyBuilder: expiration must be set")
+	}
+
+	const algorithm = "AWS4-HMAC-SHA256"
+
+	stringToSignBuilder := NewStringToSignBuilder(signingTime, region, signing.ServiceS3)
+	credential := credentials.AccessKeyID + "/" + stringToSignBuilder.Scope()
+
+	signingFields := []signing.Field{
+		{Key: "x-amz-credential", Value: credential},
+		{Key: "x-amz-algorithm", Value: algorithm},
+		{Key: "x-amz-date", Value: signingTime.LongFormat()},
+	}
+
+	if credentials.SessionToken != "" {
+		signingFields = append(signingFields, signing.Field{Key: "x-amz-security-token", Value: credentials.SessionToken})
+	}
+
+	conditions := b.conditions
+	for _, field := range signingFields {
+		conditions = append(conditions, map[string]string{field.Key: field.Value})
+	}
+
+	policy, err := json.Marshal(struct {
+		Expiration string `json:"expiration"`
+		Conditions []any  `json:"conditions"`
+	}{
+		Expiration: b.expiration.UTC().Format("2006-01-02T15:04:05.000Z"),
+		Conditions: conditions,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("PostPolicyBuilder: cannot marshal policy: %w", err)
+	}
+
+	encodedPolicy := base64.StdEncoding.EncodeToString(policy)
+
+	fields := make(map[string]string, len(b.fields)+len(signingFields)+2)
+	maps.Copy(fields, b.fields)
+
+	for _, field := range signingFields {
+		fields[field.Key] = field.Value
+	}
+
+	fields["policy"] = encodedPolicy
+	fields["x-amz-signature"] = signPostPolicy(credentials, region, signingTime, encodedPolicy)
+
+	return &PostForm{
+		Action: b.action(region),
+		Fields: fields,
+		Policy: string(policy),
+	}, nil
+}
+
+// signPostPolicy signs the base64 encoded policy document, as is.
+func signPostPolicy(credentials signing.Credentials, region string, signingTime signing.SigningTime, encodedPolicy string) string {
+	return NewSigningKey(credentials, region, signing.ServiceS3, signingTime).Sign([]byte(encodedPolicy))
+}
+
+func (b *PostPolicyBuilder) action(region string) string {
+	if b.endpoint == "" {
+		return "https://" + b.bucket + ".s3." + region + ".amazonaws.com/"
+	}
+
+	return strings.TrimSuffix(b.endpoint, "/") + "/" + b.bucket + "/"
+}
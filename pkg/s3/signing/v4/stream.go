@@ -2,62 +2,115 @@ package v4
 
 import (
 	"bytes"
+	"fmt"
 	"slices"
 	"strconv"
+	"strings"
 
 	"github.com/lvjp/s3hobby/pkg/s3/api"
 	"github.com/lvjp/s3hobby/pkg/s3/signing"
 	"github.com/lvjp/s3hobby/pkg/s3/signing/functions"
-
-	"github.com/valyala/fasthttp"
 )
 
 var emptyHash = functions.Hex(functions.SHA256Hash(nil))
 
+// DefaultChunkSize is the chunk data size used when StreamedPayloadSigner.ChunkSize is not set.
+const DefaultChunkSize = 64 * 1024
+
+// MinChunkSize is the smallest chunk data size accepted by S3, only the last chunk may be smaller.
+const MinChunkSize = 8 * 1024
+
+// Payload hashes of the streamed payloads.
+const (
+	streamingUnsignedPayloadTrailer = "STREAMING-UNSIGNED-PAYLOAD-TRAILER"
+	streamingSignedPayload          = "STREAMING-AWS4-HMAC-SHA256-PAYLOAD"
+	streamingSignedPayloadTrailer   = "STREAMING-AWS4-HMAC-SHA256-PAYLOAD-TRAILER"
+)
+
 type StreamedPayloadSigner struct {
 	SignPayload       bool
 	ForceEmptyTrailer bool
+
+	// ChunkSize is the size of the data carried by each chunk.
+	// Defaults to DefaultChunkSize and must be at least MinChunkSize.
+	ChunkSize int
 }
 
-func (signer *StreamedPayloadSigner) Sign(args signing.SigningArgs) (canonicalRequest, stringToSign, signature string, err error) {
+func (signer *StreamedPayloadSigner) Sign(args signing.SigningArgs) (signing.SigningResult, error) {
+	chunkSize, err := signer.chunkSize()
+	if err != nil {
+		return signing.SigningResult{}, err
+	}
+
+	originalBody, err := args.Request.Body()
+	if err != nil {
+		return signing.SigningResult{}, fmt.Errorf("StreamedPayloadSigner: cannot read body: %w", err)
+	}
+
 	trailer := signer.extractTrailer(args.Request)
 
-	originalBody := args.Request.Body()
-	encodedLength := GetStreamEncodedContentLength(signer.SignPayload, originalBody, trailer)
+	encodedLength, err := GetStreamEncodedContentLength(signer.SignPayload, chunkSize, originalBody, trailer)
+	if err != nil {
+		return signing.SigningResult{}, err
+	}
+
+	args.Request.SetHeader(api.HeaderContentEncoding, signer.computeContentEncoding(args.Request))
+	payloadHash := signer.computePayloadSHA256Hash(trailer != nil)
 
-	args.Request.Header.Set(api.HeaderContentEncoding, signer.computeContentEncoding(args.Request))
-	args.Request.Header.Set(api.HeaderXAmzContentSHA256, signer.computePayloadSHA256Hash(trailer != nil))
-	args.Request.Header.SetContentLength(encodedLength)
-	args.Request.Header.Set(api.HeaderXAmzDecodedContentLength, strconv.Itoa(len(originalBody)))
-	args.Request.Header.Set(api.HeaderXAmzDate, args.SigningTime.LongFormat())
+	args.Request.SetHeader(api.HeaderXAmzContentSHA256, payloadHash)
+	args.Request.SetContentLength(encodedLength)
+	args.Request.SetHeader(api.HeaderXAmzDecodedContentLength, strconv.Itoa(len(originalBody)))
+	args.Request.SetHeader(api.HeaderXAmzDate, args.SigningTime.LongFormat())
+	setSessionToken(args)
 
-	var authorizationHeader string
-	canonicalRequest, stringToSign, signature, authorizationHeader, err = getHeaderSignature(args)
+	result, authorizationHeader, err := getHeaderSignature(args, payloadHash)
 	if err != nil {
-		return "", "", "", err
+		return signing.SigningResult{}, err
 	}
 
-	args.Request.Header.Set(api.HeaderAuthorization, authorizationHeader)
+	args.Request.SetHeader(api.HeaderAuthorization, authorizationHeader)
 
 	var payloadSigner *StreamPayloadSigner
 
 	if signer.SignPayload {
 		payloadSigner = NewStreamPayloadSigner(
-			NewSigningKey(args.Credentials, args.Region, args.SigningTime),
-			signature,
-			NewStringToSignBuilder(args.SigningTime, args.Region),
+			NewSigningKey(args.Credentials, args.Region, args.EffectiveService(), args.SigningTime),
+			result.Signature,
+			NewStringToSignBuilder(args.SigningTime, args.Region, args.EffectiveService()),
 		)
 	}
 
-	args.Request.SetBodyRaw(signer.transform(payloadSigner, encodedLength, originalBody, trailer))
+	args.Request.SetBody(signer.transform(payloadSigner, chunkSize, encodedLength, originalBody, trailer))
 
-	return
+	return result, nil
+}
+
+func (signer *StreamedPayloadSigner) chunkSize() (int, error) {
+	chunkSize, err := resolveChunkSize(signer.ChunkSize)
+	if err != nil {
+		return 0, fmt.Errorf("StreamedPayloadSigner: %w", err)
+	}
+
+	return chunkSize, nil
+}
+
+// resolveChunkSize defaults a zero chunk size to DefaultChunkSize and rejects
+// the ones below MinChunkSize.
+func resolveChunkSize(chunkSize int) (int, error) {
+	switch {
+	case chunkSize == 0:
+		return DefaultChunkSize, nil
+	case chunkSize < MinChunkSize:
+		return 0, fmt.Errorf("chunk size must be at least %d bytes, got %d", MinChunkSize, chunkSize)
+	default:
+		return chunkSize, nil
+	}
 }
 
-func (*StreamedPayloadSigner) computeContentEncoding(req *fasthttp.Request) string {
+func (*StreamedPayloadSigner) computeContentEncoding(req signing.Request) string {
 	contentEncoding := "aws-chunked"
-	if actualContentEncoding := req.Header.Peek(api.HeaderContentEncoding); len(actualContentEncoding) > 0 {
-		contentEncoding += "," + string(actualContentEncoding)
+	if actualContentEncoding := req.Header(api.HeaderContentEncoding); len(actualContentEncoding) > 0 {
+		contentEncoding += "," + actualContentEncoding
 	}
 
 	return contentEncoding
@@ -67,27 +120,38 @@ func (signer *StreamedPayloadSigner) computePayloadSHA256Hash(haveTrailer bool)
 	switch {
 	case !signer.SignPayload && !haveTrailer:
 		// Will send an empty trailer
-		return "STREAMING-UNSIGNED-PAYLOAD-TRAILER"
+		return streamingUnsignedPayloadTrailer
 	case !signer.SignPayload && haveTrailer:
-		return "STREAMING-UNSIGNED-PAYLOAD-TRAILER"
+		return streamingUnsignedPayloadTrailer
 	case signer.SignPayload && !haveTrailer:
-		return "STREAMING-AWS4-HMAC-SHA256-PAYLOAD"
+		return streamingSignedPayload
 	case signer.SignPayload && haveTrailer:
-		return "STREAMING-AWS4-HMAC-SHA256-PAYLOAD-TRAILER"
+		return streamingSignedPayloadTrailer
 	default:
 		panic("unreachable")
 	}
 }
 
-func (signer *StreamedPayloadSigner) extractTrailer(req *fasthttp.Request) *TrailerBody {
+func (signer *StreamedPayloadSigner) extractTrailer(req signing.Request) *TrailerBody {
 	var trailer *TrailerBody
 
-	if trailerName := req.Header.Peek(api.HeaderXAmzTrailer); len(trailerName) > 0 {
-		trailer = &TrailerBody{
-			Name:  string(trailerName),
-			Value: string(req.Header.PeekBytes(trailerName)),
+	// Values are cloned since deleting headers may reuse their memory.
+	if trailerNames := strings.Clone(req.Header(api.HeaderXAmzTrailer)); len(trailerNames) > 0 {
+		trailer = &TrailerBody{}
+
+		// The trailer header is a comma separated list of header names.
+		for name := range strings.SplitSeq(trailerNames, ",") {
+			name = functions.LowerCase(functions.Trim(name))
+			if len(name) == 0 {
+				continue
+			}
+
+			trailer.Headers = append(trailer.Headers, TrailerHeader{
+				Name:  name,
+				Value: strings.Clone(functions.Trim(req.Header(name))),
+			})
+			req.DelHeader(name)
 		}
-		req.Header.DelBytes(trailerName)
 	} else if signer.ForceEmptyTrailer {
 		trailer = &TrailerBody{}
 	}
@@ -95,11 +159,11 @@ func (signer *StreamedPayloadSigner) extractTrailer(req *fasthttp.Request) *Trai
 	return trailer
 }
 
-func (signer *StreamedPayloadSigner) transform(payloadSigner *StreamPayloadSigner, encodedLen int, originalBody []byte, trailer *TrailerBody) []byte {
+func (signer *StreamedPayloadSigner) transform(payloadSigner *StreamPayloadSigner, chunkSize, encodedLen int, originalBody []byte, trailer *TrailerBody) []byte {
 	var buf bytes.Buffer
 	buf.Grow(encodedLen)
 
-	for chunk := range slices.Chunk(originalBody, chunkDataSize) {
+	for chunk := range slices.Chunk(originalBody, chunkSize) {
 		signer.writeChunk(payloadSigner, &buf, chunk)
 	}
 
@@ -185,48 +249,71 @@ func (s *StreamPayloadSigner) TrailerSignature(payload []byte) (stringToSign, si
 	return
 }
 
-const chunkDataSize = 64 * 1024
 const trailerSeparator = ":"
 
 var crlf = []byte{'\r', '\n'}
 
-type TrailerBody struct {
+type TrailerHeader struct {
 	Name  string
 	Value string
 }
 
+// TrailerBody holds the trailing headers sent after the final chunk, in the
+// order they are listed by the x-amz-trailer header.
+type TrailerBody struct {
+	Headers []TrailerHeader
+}
+
 func (t *TrailerBody) Len() int {
-	if len(t.Name) == 0 {
-		return 0
+	var ret int
+
+	for _, header := range t.Headers {
+		ret += len(header.Name) + len(trailerSeparator) + len(header.Value) + len(crlf)
 	}
 
-	return len(t.Name) + len(trailerSeparator) + len(t.Value) + len(crlf)
+	return ret
 }
 
 func (t *TrailerBody) StringtoSign() string {
-	if len(t.Name) == 0 {
+	if len(t.Headers) == 0 {
 		return "\n"
 	}
 
-	return t.Name + trailerSeparator + t.Value + "\n"
+	var ret strings.Builder
+
+	for _, header := range t.Headers {
+		ret.WriteString(header.Name)
+		ret.WriteString(trailerSeparator)
+		ret.WriteString(header.Value)
+		ret.WriteByte('\n')
+	}
+
+	return ret.String()
 }
 
 func (t *TrailerBody) Bytes() []byte {
 	var buf bytes.Buffer
 	buf.Grow(t.Len())
 
-	var signBody string
-
-	if len(t.Name) > 0 {
-		signBody = t.Name + trailerSeparator + t.Value
-		buf.WriteString(signBody)
+	for _, header := range t.Headers {
+		buf.WriteString(header.Name)
+		buf.WriteString(trailerSeparator)
+		buf.WriteString(header.Value)
 		buf.Write(crlf)
 	}
 
 	return buf.Bytes()
 }
 
-func GetStreamEncodedContentLength(isSigned bool, originalBody []byte, trailer *TrailerBody) int {
+// GetStreamEncodedContentLength returns the length of the aws-chunked encoding
+// of the body. The chunk data size defaults to DefaultChunkSize when zero, and
+// must be at least MinChunkSize.
+func GetStreamEncodedContentLength(isSigned bool, chunkDataSize int, originalBody []byte, trailer *TrailerBody) (int, error) {
+	chunkDataSize, err := resolveChunkSize(chunkDataSize)
+	if err != nil {
+		return 0, fmt.Errorf("GetStreamEncodedContentLength: %w", err)
+	}
+
 	const signatureValueLen = 64
 	const signatureSize = len(";chunk-signature=") + signatureValueLen
 
@@ -234,7 +321,7 @@ func GetStreamEncodedContentLength(isSigned bool, originalBody []byte, trailer *
 
 	bodyLen := decodedContentLength
 	if nbChunk := decodedContentLength / chunkDataSize; nbChunk > 0 {
-		chunkSize := len(strconv.FormatInt(chunkDataSize, 16)) + len(crlf)
+		chunkSize := len(strconv.FormatInt(int64(chunkDataSize), 16)) + len(crlf)
 		if isSigned {
 			chunkSize += signatureSize
 		}
@@ -265,5 +352,5 @@ func GetStreamEncodedContentLength(isSigned bool, originalBody []byte, trailer *
 
 	bodyLen += len(crlf)
 
-	return bodyLen
+	return bodyLen, nil
 }
@@ -1,8 +1,6 @@
 package v4
 
 import (
-	"bytes"
-
 	"github.com/lvjp/s3hobby/pkg/s3/api"
 	"github.com/lvjp/s3hobby/pkg/s3/signing"
 )
@@ -13,9 +11,9 @@ func NewDynamicSigner() signing.Signer {
 	return &dynamicSigner{}
 }
 
-func (*dynamicSigner) Sign(args signing.SigningArgs) (canonicalRequest, stringToSign, signature string, err error) {
-	needStream := len(args.Request.Header.Peek(api.HeaderXAmzTrailer)) > 0
-	signPayload := bytes.Equal(args.Request.URI().Scheme(), []byte("http"))
+func (*dynamicSigner) Sign(args signing.SigningArgs) (signing.SigningResult, error) {
+	needStream := len(args.Request.Header(api.HeaderXAmzTrailer)) > 0
+	signPayload := args.Request.Scheme() == "http"
 
 	var signer signing.Signer = &PlainPayloadSigner{SignPayload: signPayload}
 	if needStream {
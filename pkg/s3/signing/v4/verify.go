@@ -0,0 +1,328 @@
+package v4
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lvjp/s3hobby/pkg/s3/api"
+	"github.com/lvjp/s3hobby/pkg/s3/signing"
+	"github.com/lvjp/s3hobby/pkg/s3/signing/functions"
+)
+
+// DefaultMaxSkew is the clock skew tolerated by S3 between the signing time
+// and the server time.
+const DefaultMaxSkew = 15 * time.Minute
+
+var (
+	ErrMissingAuthorization   = errors.New("Verifier: missing authorization header")
+	ErrMalformedAuthorization = errors.New("Verifier: malformed authorization header")
+	ErrRequestTimeTooSkewed   = errors.New("Verifier: request time too skewed")
+	ErrContentSHA256Mismatch  = errors.New("Verifier: payload hash does not match the body")
+	ErrSignatureDoesNotMatch  = errors.New("Verifier: signature does not match")
+
+	ErrUnsupportedPayload         = errors.New("Verifier: unsupported payload")
+	ErrMalformedChunkedBody       = errors.New("Verifier: malformed aws-chunked body")
+	ErrChunkSignatureDoesNotMatch = errors.New("Verifier: chunk signature does not match")
+)
+
+// Verifier is the server side counterpart of the header signers: it checks the
+// Authorization header of a received request.
+type Verifier struct {
+	// Credentials returns the credentials owning the access key ID.
+	Credentials func(accessKeyID string) (signing.Credentials, error)
+
+	// Region restricts the accepted credential scope region when set.
+	Region string
+
+	// Service defaults to signing.ServiceS3.
+	Service string
+
+	// Clock defaults to signing.SystemClock.
+	Clock signing.Clock
+
+	// MaxSkew defaults to DefaultMaxSkew.
+	MaxSkew time.Duration
+}
+
+// Verify checks the request signature. The returned result holds the values
+// computed by the server side, even on signature mismatch, so that they can be
+// reported to the client.
+func (v *Verifier) Verify(req signing.Request) (signing.SigningResult, error) {
+	authorization, err := parseAuthorization(req.Header(api.HeaderAuthorization))
+	if err != nil {
+		return signing.SigningResult{}, err
+	}
+
+	signingTime, err := v.checkSigningTime(req)
+	if err != nil {
+		return signing.SigningResult{}, err
+	}
+
+	if err := v.checkScope(authorization, signingTime); err != nil {
+		return signing.SigningResult{}, err
+	}
+
+	credentials, err := v.Credentials(authorization.accessKeyID)
+	if err != nil {
+		return signing.SigningResult{}, fmt.Errorf("Verifier: cannot get credentials of %q: %w", authorization.accessKeyID, err)
+	}
+
+	payloadHash, err := verifyPayloadHash(req)
+	if err != nil {
+		return signing.SigningResult{}, err
+	}
+
+	builder := acquireCanonicalBuilder()
+	defer releaseCanonicalBuilder(builder)
+
+	builder.build(req, payloadHash, authorization.signedHeaders)
+
+	if string(builder.signedHeaders) != authorization.signedHeaders {
+		return signing.SigningResult{}, fmt.Errorf("%w: signed headers %q are not all present", ErrMalformedAuthorization, authorization.signedHeaders)
+	}
+
+	stringToSignBuilder := NewStringToSignBuilder(signingTime, authorization.region, authorization.service)
+	stringToSign := stringToSignBuilder.BuildWith("AWS4-HMAC-SHA256", functions.Hex(functions.SHA256Hash(builder.canonicalRequest)))
+
+	signingKey := NewSigningKey(credentials, authorization.region, authorization.service, signingTime)
+
+	result := signing.SigningResult{
+		CanonicalRequest: string(builder.canonicalRequest),
+		StringToSign:     stringToSign,
+		Signature:        signingKey.Sign([]byte(stringToSign)),
+		SignedHeaders:    string(builder.signedHeaders),
+		Scope:            stringToSignBuilder.Scope(),
+	}
+
+	if !signatureEqual(result.Signature, authorization.signature) {
+		return result, ErrSignatureDoesNotMatch
+	}
+
+	if payloadHash == streamingSignedPayload || payloadHash == streamingSignedPayloadTrailer {
+		// The seed signature is the one of the headers, each chunk signature
+		// then chains on the previous one.
+		payloadSigner := NewStreamPayloadSigner(signingKey, result.Signature, stringToSignBuilder)
+
+		body, err := req.Body()
+		if err != nil {
+			return result, fmt.Errorf("Verifier: cannot read body: %w", err)
+		}
+
+		if err := verifyChunks(payloadSigner, body, payloadHash == streamingSignedPayloadTrailer); err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+func (v *Verifier) checkSigningTime(req signing.Request) (signing.SigningTime, error) {
+	signingTime, err := signing.ParseSigningTime(req.Header(api.HeaderXAmzDate))
+	if err != nil {
+		return signing.SigningTime{}, fmt.Errorf("%w: invalid %q header: %w", ErrMalformedAuthorization, api.HeaderXAmzDate, err)
+	}
+
+	clock := v.Clock
+	if clock == nil {
+		clock = signing.SystemClock
+	}
+
+	maxSkew := v.MaxSkew
+	if maxSkew == 0 {
+		maxSkew = DefaultMaxSkew
+	}
+
+	if skew := clock.Now().Sub(signingTime.Time()); skew.Abs() > maxSkew {
+		return signing.SigningTime{}, fmt.Errorf("%w: %s", ErrRequestTimeTooSkewed, skew)
+	}
+
+	return signingTime, nil
+}
+
+func (v *Verifier) checkScope(authorization authorizationHeader, signingTime signing.SigningTime) error {
+	service := v.Service
+	if service == "" {
+		service = signing.ServiceS3
+	}
+
+	switch {
+	case authorization.service != service:
+		return fmt.Errorf("%w: unexpected service %q", ErrMalformedAuthorization, authorization.service)
+	case v.Region != "" && authorization.region != v.Region:
+		return fmt.Errorf("%w: unexpected region %q", ErrMalformedAuthorization, authorization.region)
+	case authorization.date != signingTime.ShortFormat():
+		return fmt.Errorf("%w: unexpected scope date %q", ErrMalformedAuthorization, authorization.date)
+	default:
+		return nil
+	}
+}
+
+// verifyPayloadHash returns the payload hash of the canonical request. S3
+// clients send it in the x-amz-content-sha256 header, other services hash the
+// body. The chunks of signed streaming payloads are checked once the seed
+// signature is verified, the other streaming algorithms are not supported.
+func verifyPayloadHash(req signing.Request) (string, error) {
+	body, err := req.Body()
+	if err != nil {
+		return "", fmt.Errorf("Verifier: cannot read body: %w", err)
+	}
+
+	bodyHash := functions.Hex(functions.SHA256Hash(body))
+
+	payloadHash := req.Header(api.HeaderXAmzContentSHA256)
+	switch {
+	case payloadHash == "":
+		return bodyHash, nil
+	case payloadHash == "UNSIGNED-PAYLOAD", payloadHash == streamingUnsignedPayloadTrailer,
+		payloadHash == streamingSignedPayload, payloadHash == streamingSignedPayloadTrailer:
+		return payloadHash, nil
+	case strings.HasPrefix(payloadHash, "STREAMING-"):
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedPayload, payloadHash)
+	case payloadHash != bodyHash:
+		return "", ErrContentSHA256Mismatch
+	default:
+		return payloadHash, nil
+	}
+}
+
+// verifyChunks checks the chunk signatures of an aws-chunked body, then the
+// signature of the trailing headers when present.
+func verifyChunks(payloadSigner *StreamPayloadSigner, body []byte, haveTrailer bool) error {
+	for index := 0; ; index++ {
+		line, rest, found := bytes.Cut(body, crlf)
+		if !found {
+			return fmt.Errorf("%w: chunk %d has no header", ErrMalformedChunkedBody, index)
+		}
+
+		hexSize, signature, found := strings.Cut(string(line), ";chunk-signature=")
+		if !found {
+			return fmt.Errorf("%w: chunk %d is not signed", ErrMalformedChunkedBody, index)
+		}
+
+		size, err := strconv.ParseUint(hexSize, 16, 31)
+		if err != nil || int(size) > len(rest) {
+			return fmt.Errorf("%w: chunk %d has an invalid size %q", ErrMalformedChunkedBody, index, hexSize)
+		}
+
+		if _, expected := payloadSigner.ChunkSignature(rest[:size]); !signatureEqual(expected, signature) {
+			return fmt.Errorf("%w: chunk %d", ErrChunkSignatureDoesNotMatch, index)
+		}
+
+		if size == 0 {
+			body = rest
+			break
+		}
+
+		if !bytes.HasPrefix(rest[size:], crlf) {
+			return fmt.Errorf("%w: chunk %d is not terminated", ErrMalformedChunkedBody, index)
+		}
+
+		body = rest[int(size)+len(crlf):]
+	}
+
+	if haveTrailer {
+		var err error
+		if body, err = verifyTrailer(payloadSigner, body); err != nil {
+			return err
+		}
+	}
+
+	if !bytes.Equal(body, crlf) {
+		return fmt.Errorf("%w: unexpected data after the final chunk", ErrMalformedChunkedBody)
+	}
+
+	return nil
+}
+
+// verifyTrailer checks the signature of the trailing headers, it returns the
+// remaining body.
+func verifyTrailer(payloadSigner *StreamPayloadSigner, body []byte) ([]byte, error) {
+	var trailer TrailerBody
+
+	for {
+		line, rest, found := bytes.Cut(body, crlf)
+		if !found {
+			return nil, fmt.Errorf("%w: missing trailer signature", ErrMalformedChunkedBody)
+		}
+
+		name, value, found := strings.Cut(string(line), trailerSeparator)
+		if !found {
+			return nil, fmt.Errorf("%w: invalid trailing header %q", ErrMalformedChunkedBody, line)
+		}
+
+		body = rest
+
+		if name == api.HeaderXAmzTrailerSignature {
+			if _, expected := payloadSigner.TrailerSignature([]byte(trailer.StringtoSign())); !signatureEqual(expected, value) {
+				return nil, fmt.Errorf("%w: trailer", ErrChunkSignatureDoesNotMatch)
+			}
+
+			return body, nil
+		}
+
+		trailer.Headers = append(trailer.Headers, TrailerHeader{Name: name, Value: value})
+	}
+}
+
+func signatureEqual(expected, actual string) bool {
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(actual)) == 1
+}
+
+type authorizationHeader struct {
+	accessKeyID string
+	date        string
+	region      string
+	service     string
+
+	signedHeaders string
+	signature     string
+}
+
+// parseAuthorization parses a header like:
+// AWS4-HMAC-SHA256 Credential=<key>/<date>/<region>/<service>/aws4_request, SignedHeaders=<headers>, Signature=<signature>.
+func parseAuthorization(header string) (authorizationHeader, error) {
+	if header == "" {
+		return authorizationHeader{}, ErrMissingAuthorization
+	}
+
+	params, found := strings.CutPrefix(header, "AWS4-HMAC-SHA256 ")
+	if !found {
+		return authorizationHeader{}, fmt.Errorf("%w: unsupported algorithm", ErrMalformedAuthorization)
+	}
+
+	var ret authorizationHeader
+	var credential string
+
+	for param := range strings.SplitSeq(params, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+
+		switch key {
+		case "Credential":
+			credential = value
+		case "SignedHeaders":
+			ret.signedHeaders = value
+		case "Signature":
+			ret.signature = value
+		default:
+			return authorizationHeader{}, fmt.Errorf("%w: unknown parameter %q", ErrMalformedAuthorization, key)
+		}
+	}
+
+	scope := strings.Split(credential, "/")
+	if len(scope) != 5 || scope[4] != "aws4_request" { //nolint:mnd // key/date/region/service/aws4_request
+		return authorizationHeader{}, fmt.Errorf("%w: invalid credential %q", ErrMalformedAuthorization, credential)
+	}
+
+	ret.accessKeyID, ret.date, ret.region, ret.service = scope[0], scope[1], scope[2], scope[3]
+
+	if ret.signedHeaders == "" || ret.signature == "" {
+		return authorizationHeader{}, fmt.Errorf("%w: missing signed headers or signature", ErrMalformedAuthorization)
+	}
+
+	return ret, nil
+}